@@ -1,10 +1,18 @@
 package croot_test
 
 import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
 	"math/rand"
 	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/go-hep/croot"
@@ -662,4 +670,2616 @@ func TestTreeStructString(t *testing.T) {
 	}
 }
 
+func TestMinimizer(t *testing.T) {
+	m, err := croot.NewMinimizer("Minuit2", "Migrad")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer m.Delete()
+
+	// chi2-like parabola with minimum at (1, -2)
+	m.SetFunction(2, func(p []float64) float64 {
+		x := p[0] - 1
+		y := p[1] + 2
+		return x*x + 4*y*y
+	})
+	m.SetErrorDef(1)
+	m.SetPrintLevel(0)
+
+	err = m.SetVariable(0, "x", 0, 0.1)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = m.SetLimitedVariable(1, "y", 0, 0.1, -10, 10)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	err = m.Minimize()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = m.Hesse()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	x := m.X()
+	if math.Abs(x[0]-1) > 1e-3 || math.Abs(x[1]+2) > 1e-3 {
+		t.Errorf("invalid minimum. expected [1 -2], got %v", x)
+	}
+	if m.MinValue() > 1e-6 {
+		t.Errorf("invalid minimum value. got %v", m.MinValue())
+	}
+
+	errs := m.Errors()
+	if math.Abs(errs[0]-1) > 1e-2 || math.Abs(errs[1]-0.5) > 1e-2 {
+		t.Errorf("invalid errors. expected [1 0.5], got %v", errs)
+	}
+
+	lo, up, err := m.Minos(0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if math.Abs(lo+1) > 1e-2 || math.Abs(up-1) > 1e-2 {
+		t.Errorf("invalid minos errors. expected [-1 1], got [%v %v]", lo, up)
+	}
+
+	// fix x away from the minimum and minimize again.
+	err = m.SetFixedVariable(0, "x", 3)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if !m.IsFixedVariable(0) {
+		t.Errorf("expected variable [0] to be fixed")
+	}
+	err = m.Minimize()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	x = m.X()
+	if x[0] != 3 || math.Abs(x[1]+2) > 1e-3 {
+		t.Errorf("invalid minimum. expected [3 -2], got %v", x)
+	}
+}
+
+func TestMinimizerPanic(t *testing.T) {
+	m, err := croot.NewMinimizer("Minuit2", "Migrad")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer m.Delete()
+
+	m.SetFunction(1, func(p []float64) float64 {
+		if p[0] > 2 {
+			panic("out of range")
+		}
+		return (p[0] - 5) * (p[0] - 5)
+	})
+	m.SetPrintLevel(0)
+	err = m.SetVariable(0, "x", 0, 0.1)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	err = m.Minimize()
+	if err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Fatalf("expected the panic of the objective function to be reported, got %v", err)
+	}
+}
+
+func TestRandom3(t *testing.T) {
+	const seed = 4357
+	const n = 100
+
+	r1 := croot.NewRandom3(seed)
+	defer r1.Delete()
+	r2 := croot.NewRandom3(seed)
+	defer r2.Delete()
+
+	v1 := r1.RndmArray(n)
+	v2 := make([]float64, n)
+	for i := range v2 {
+		v2[i] = r2.Rndm(0)
+	}
+	if !reflect.DeepEqual(v1, v2) {
+		t.Fatalf("generators with the same seed differ")
+	}
+	for _, v := range v1 {
+		if v <= 0 || v >= 1 {
+			t.Errorf("Rndm out of range: %v", v)
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		if v := r1.Uniform(-2, 3); v < -2 || v > 3 {
+			t.Errorf("Uniform out of range: %v", v)
+		}
+		if v := r1.Exp(2); v < 0 {
+			t.Errorf("Exp out of range: %v", v)
+		}
+		if v := r1.Poisson(3); v < 0 {
+			t.Errorf("Poisson out of range: %v", v)
+		}
+		if v := r1.Binomial(10, 0.2); v < 0 || v > 10 {
+			t.Errorf("Binomial out of range: %v", v)
+		}
+		x, y, z := r1.Sphere(2)
+		if rr := math.Sqrt(x*x + y*y + z*z); math.Abs(rr-2) > 1e-9 {
+			t.Errorf("Sphere: invalid radius %v", rr)
+		}
+	}
+
+	// reproducible math/rand streams
+	src1 := rand.New(croot.NewSource(r1))
+	src2 := rand.New(croot.NewSource(r2))
+	src1.Seed(42)
+	src2.Seed(42)
+	for i := 0; i < n; i++ {
+		a := src1.NormFloat64()
+		b := src2.NormFloat64()
+		if a != b {
+			t.Fatalf("math/rand streams differ at %d: %v != %v", i, a, b)
+		}
+	}
+
+	// 64bit seeds are not truncated to ROOT's "unique seed" 0.
+	for _, seed := range []int64{0, 1 << 32, -1 << 63} {
+		src1.Seed(seed)
+		if r1.GetSeed() == 0 {
+			t.Errorf("seed %d: got a zero ROOT seed", seed)
+		}
+		a := src1.Int63()
+		src1.Seed(seed)
+		if b := src1.Int63(); a != b {
+			t.Errorf("seed %d: irreproducible stream (%v != %v)", seed, a, b)
+		}
+	}
+	src1.Seed(1)
+	a := src1.Int63()
+	src1.Seed(1 << 32)
+	if b := src1.Int63(); a == b {
+		t.Errorf("seeds 1 and 1<<32 give the same stream")
+	}
+
+	// ROOT owns gRandom
+	croot.GRandom.Delete()
+	if v := croot.GRandom.Rndm(0); v <= 0 || v >= 1 {
+		t.Errorf("GRandom unusable after Delete: %v", v)
+	}
+}
+
+// FlatEvent is the content of the simple tree created by createFlatTree
+type FlatEvent struct {
+	I int64
+	X float64
+	Y float64
+}
+
+// createFlatTree writes a tree "tree" with branches i, x=i and y=2*i
+func createFlatTree(t testing.TB, fname string, evtmax int64) {
+	f, err := croot.OpenFile(fname, "recreate", "croot flat file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+
+	tree := croot.NewTree("tree", "tree", 32)
+	var e FlatEvent
+	for _, br := range []struct {
+		name string
+		ptr  interface{}
+		leaf string
+	}{
+		{"i", &e.I, "i/L"},
+		{"x", &e.X, "x/D"},
+		{"y", &e.Y, "y/D"},
+	} {
+		_, err = tree.Branch2(br.name, br.ptr, br.leaf, 32000)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+
+	for i := int64(0); i != evtmax; i++ {
+		e.I = i
+		e.X = float64(i)
+		e.Y = 2 * float64(i)
+		_, err = tree.Fill()
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+	f.Write("", 0, 0)
+}
+
+// ArrayEvent is the content of the entries of the trees written by
+// createArrayTree.
+type ArrayEvent struct {
+	N   int32
+	Fix [3]float64
+	Mat [6]float32 // a 2x3 matrix
+	V   []float32  // counted by N
+	Vec []float64  // a std::vector
+	S   struct {
+		A int32
+		B float64
+	}
+}
+
+// newArrayEvent returns the i-th entry of the trees written by
+// createArrayTree.
+func newArrayEvent(i int64) ArrayEvent {
+	var e ArrayEvent
+	for j := range e.Fix {
+		e.Fix[j] = float64(10*i) + float64(j)
+	}
+	for j := range e.Mat {
+		e.Mat[j] = float32(i) + float32(j)/10
+	}
+	for j := int64(0); j <= i%3; j++ {
+		e.V = append(e.V, float32(i+j))
+	}
+	e.N = int32(len(e.V))
+	for j := int64(0); j < i%4; j++ {
+		e.Vec = append(e.Vec, float64(-i-j))
+	}
+	e.S.A = int32(i)
+	e.S.B = float64(i) + 0.5
+	return e
+}
+
+// createArrayTree writes a tree "tree" with fixed-size arrays (fix[3],
+// mat[2][3]), a variable-size array (v[n]), a std::vector (vec) and a
+// struct (s, with the leaves a and b).
+func createArrayTree(t testing.TB, fname string, evtmax int64) {
+	f, err := croot.OpenFile(fname, "recreate", "croot array file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+
+	tree := croot.NewTree("tree", "tree", 32)
+	var e ArrayEvent
+	for _, br := range []struct {
+		name string
+		ptr  interface{}
+		leaf string
+	}{
+		{"n", &e.N, "n/I"},
+		{"fix", &e.Fix, "fix[3]/D"},
+		{"mat", &e.Mat, "mat[2][3]/F"},
+		{"v", &e.V, "v[n]/F"},
+		{"s", &e.S, "a/I:b/D"},
+	} {
+		_, err = tree.Branch2(br.name, br.ptr, br.leaf, 32000)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+	_, err = tree.Branch("vec", &e.Vec, 32000, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for i := int64(0); i != evtmax; i++ {
+		e = newArrayEvent(i)
+		_, err = tree.Fill()
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+	f.Write("", 0, 0)
+}
+
+// createSplitTree writes a tree "tree" with a split branch "evt" holding
+// an Event.
+func createSplitTree(t testing.TB, fname string, evtmax int64) {
+	f, err := croot.OpenFile(fname, "recreate", "croot split file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+
+	tree := croot.NewTree("tree", "tree", 99)
+	var e Event
+	_, err = tree.Branch("evt", &e, 32000, 99)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	for i := int64(0); i != evtmax; i++ {
+		e.I = i
+		e.A = Det{E: float64(i), T: float64(2 * i)}
+		e.B = Det{E: float64(-i), T: float64(-2 * i)}
+		_, err = tree.Fill()
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+	f.Write("", 0, 0)
+}
+
+func TestTreeDraw(t *testing.T) {
+	const fname = "tree-draw.root"
+	const evtmax = 100
+	createFlatTree(t, fname, evtmax)
+	defer os.Remove(fname)
+
+	f, err := croot.OpenFile(fname, "read", "croot flat file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+
+	tree := f.GetTree("tree")
+	n, err := tree.Draw("x:y", "i%2==0", "", evtmax, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if n != evtmax/2 {
+		t.Fatalf("expected %d selected rows, got %d", evtmax/2, n)
+	}
+
+	v1 := tree.GetV1()
+	v2 := tree.GetV2()
+	if int64(len(v1)) != n || int64(len(v2)) != n {
+		t.Fatalf("invalid column sizes: %d %d (expected %d)", len(v1), len(v2), n)
+	}
+	for i := range v1 {
+		if v1[i] != float64(2*i) || v2[i] != 2*v1[i] {
+			t.Errorf("row %d: invalid values x=%v y=%v", i, v1[i], v2[i])
+		}
+	}
+	if v0 := tree.GetVal(0); !reflect.DeepEqual(v0, v1) {
+		t.Errorf("GetVal(0) and GetV1 differ")
+	}
+
+	_, err = tree.Draw("not_a_branch", "", "", evtmax, 0)
+	if err == nil {
+		t.Errorf("expected an error drawing an invalid expression")
+	}
+}
+
+func TestTreeDrawArrays(t *testing.T) {
+	const fname = "tree-draw-arrays.root"
+	const evtmax = 100
+	const nvals = 5
+	defer os.Remove(fname)
+
+	f, err := croot.OpenFile(fname, "recreate", "croot arrays file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	tree := croot.NewTree("tree", "tree", 32)
+	var v [nvals]float64
+	_, err = tree.Branch2("v", &v, fmt.Sprintf("v[%d]/D", nvals), 32000)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	for i := 0; i != evtmax; i++ {
+		for j := range v {
+			v[j] = float64(i*nvals + j)
+		}
+		_, err = tree.Fill()
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+	f.Write("", 0, 0)
+	f.Close("")
+
+	f, err = croot.OpenFile(fname, "read", "croot arrays file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+	tree = f.GetTree("tree")
+
+	// more rows than entries: the estimate has to be raised.
+	n, err := tree.Draw("v", "", "", evtmax, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if n != evtmax*nvals {
+		t.Fatalf("expected %d selected rows, got %d", evtmax*nvals, n)
+	}
+	v1 := tree.GetV1()
+	if int64(len(v1)) != n {
+		t.Fatalf("expected %d values, got %d", n, len(v1))
+	}
+	for i, x := range v1 {
+		if x != float64(i) {
+			t.Fatalf("row %d: expected %v, got %v", i, float64(i), x)
+		}
+	}
+}
+
+func TestFormula(t *testing.T) {
+	const fname = "tree-formula.root"
+	const evtmax = 10
+	createFlatTree(t, fname, evtmax)
+	defer os.Remove(fname)
+
+	f, err := croot.OpenFile(fname, "read", "croot flat file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+	tree := f.GetTree("tree")
+
+	_, err = croot.NewFormula("bad", "sqrt(nope)", tree)
+	if err == nil {
+		t.Fatalf("expected an error for an invalid expression")
+	}
+
+	form, err := croot.NewFormula("r", "sqrt(x*x+y*y)", tree)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer form.Delete()
+
+	for i := int64(0); i != evtmax; i++ {
+		tree.LoadTree(i)
+		if n := form.GetNdata(); n != 1 {
+			t.Fatalf("entry %d: expected 1 value, got %d", i, n)
+		}
+		want := math.Sqrt(5) * float64(i)
+		if got := form.EvalInstance(0); math.Abs(got-want) > 1e-9 {
+			t.Errorf("entry %d: got %v, want %v", i, got, want)
+		}
+	}
+
+	// same file twice through a chain
+	chain := croot.NewChain("tree", "chain")
+	defer chain.Delete()
+	chain.Add(fname, -1)
+	chain.Add(fname, -1)
+	if n := chain.GetEntries(); n != 2*evtmax {
+		t.Fatalf("expected %d entries in chain, got %d", 2*evtmax, n)
+	}
+
+	cform, err := croot.NewFormula("x2", "2*x", chain)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer cform.Delete()
+	for i := int64(0); i != 2*evtmax; i++ {
+		chain.LoadTree(i)
+		cform.GetNdata()
+		want := 2 * float64(i%evtmax)
+		if got := cform.EvalInstance(0); got != want {
+			t.Errorf("chain entry %d: got %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestEntryList(t *testing.T) {
+	const fname = "tree-elist.root"
+	const lname = "tree-elist-sel.root"
+	const evtmax = 100
+	createFlatTree(t, fname, evtmax)
+	defer os.Remove(fname)
+	defer os.Remove(lname)
+
+	f, err := croot.OpenFile(fname, "read", "croot flat file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+	tree := f.GetTree("tree")
+
+	sel, err := croot.NewEntryListFromSelection(tree, "sel", "i%3==0")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if n := sel.GetN(); n != 34 {
+		t.Fatalf("expected 34 selected entries, got %d", n)
+	}
+
+	var e FlatEvent
+	tree.SetBranchAddress("i", &e.I)
+	tree.SetBranchAddress("x", &e.X)
+	fct, err := croot.NewEntryListFunc(tree, "fct", func(entry int64) bool {
+		return e.I%3 == 0
+	})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer fct.Delete()
+	if fct.GetN() != sel.GetN() {
+		t.Fatalf("selection and predicate differ: %d != %d", sel.GetN(), fct.GetN())
+	}
+	for i := int64(0); i < fct.GetN(); i++ {
+		if a, b := sel.GetEntry(i), fct.GetEntry(i); a != b || a != 3*i {
+			t.Fatalf("entry %d: %d != %d", i, a, b)
+		}
+	}
+
+	// persistency
+	{
+		out, err := croot.OpenFile(lname, "recreate", "croot entry list", 1, 0)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		out.Cd("")
+		sel.Write("sel", 0, 0)
+		out.Close("")
+	}
+
+	in, err := croot.OpenFile(lname, "read", "croot entry list", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer in.Close("")
+
+	elist, ok := in.Get("sel").(croot.EntryList)
+	if !ok {
+		t.Fatalf("could not reload entry list")
+	}
+	if elist.GetN() != sel.GetN() {
+		t.Fatalf("reloaded entry list differs: %d != %d", elist.GetN(), sel.GetN())
+	}
+
+	tree.SetEntryList(elist)
+	n := int64(0)
+	for i := int64(0); ; i++ {
+		entry := tree.GetEntryNumber(i)
+		if entry < 0 {
+			break
+		}
+		if tree.GetEntry(entry, 1) <= 0 {
+			t.Fatalf("could not read entry %d", entry)
+		}
+		if e.I%3 != 0 || e.X != float64(e.I) {
+			t.Errorf("entry %d: unexpected values %+v", entry, e)
+		}
+		n++
+	}
+	if n != elist.GetN() {
+		t.Errorf("visited %d entries, expected %d", n, elist.GetN())
+	}
+
+	n = 0
+	for i := int64(0); tree.GetEntryFromList(i, 1) > 0; i++ {
+		if e.I != 3*i || e.X != float64(e.I) {
+			t.Errorf("listed entry %d: unexpected values %+v", i, e)
+		}
+		n++
+	}
+	if n != elist.GetN() {
+		t.Errorf("visited %d listed entries, expected %d", n, elist.GetN())
+	}
+
+	for _, i := range []int64{-1, elist.GetN(), 1 << 32, math.MaxInt64} {
+		if entry := elist.GetEntry(i); entry != -1 {
+			t.Errorf("index %d: expected no entry, got %d", i, entry)
+		}
+	}
+
+	tree.SetEntryList(nil)
+	n = 0
+	for i := int64(0); tree.GetEntryFromList(i, 1) > 0; i++ {
+		n++
+	}
+	if n != evtmax {
+		t.Errorf("visited %d entries without list, expected %d", n, evtmax)
+	}
+}
+
+func TestTreeFriend(t *testing.T) {
+	const fname = "tree-main.root"
+	const cname = "tree-calib.root"
+	const evtmax = 100
+	createFlatTree(t, fname, evtmax)
+	defer os.Remove(fname)
+
+	// side-car tree, aligned entry-by-entry with the main one.
+	{
+		f, err := croot.OpenFile(cname, "recreate", "croot calib file", 1, 0)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		tree := croot.NewTree("calib", "calib", 32)
+		var x float64
+		_, err = tree.Branch2("x", &x, "x/D", 32000)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		for i := 0; i != evtmax; i++ {
+			x = 10 * float64(i)
+			_, err = tree.Fill()
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+		}
+
+		// side-car tree, in reverse order, to be read through its index.
+		rtree := croot.NewTree("calib2", "calib2", 32)
+		var ri int64
+		_, err = rtree.Branch2("i", &ri, "i/L", 32000)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		_, err = rtree.Branch2("x", &x, "x/D", 32000)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		for i := evtmax - 1; i >= 0; i-- {
+			ri = int64(i)
+			x = 100 * float64(i)
+			_, err = rtree.Fill()
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+		}
+		f.Write("", 0, 0)
+		f.Close("")
+	}
+	defer os.Remove(cname)
+
+	f, err := croot.OpenFile(fname, "read", "croot flat file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+	tree := f.GetTree("tree")
+
+	_, err = tree.AddFriend("calib", cname, "cal")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// overlapping alias: "cal.v2.x" must resolve to calib2, not to calib.
+	rtree, err := tree.AddFriend("calib2", cname, "cal.v2")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = rtree.BuildIndex("i", "")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	var x, calx, calx2 float64
+	if rc := tree.SetBranchAddress("x", &x); rc < 0 {
+		t.Fatalf("could not set branch address of [x] (rc=%d)", rc)
+	}
+	if rc := tree.SetBranchAddress("cal.x", &calx); rc < 0 {
+		t.Fatalf("could not set branch address of [cal.x] (rc=%d)", rc)
+	}
+	if rc := tree.SetBranchAddress("cal.v2.x", &calx2); rc < 0 {
+		t.Fatalf("could not set branch address of [cal.v2.x] (rc=%d)", rc)
+	}
+
+	for i := int64(0); i != evtmax; i++ {
+		if tree.GetEntry(i, 1) <= 0 {
+			t.Fatalf("could not read entry %d", i)
+		}
+		if x != float64(i) || calx != 10*x {
+			t.Errorf("entry %d: x=%v cal.x=%v", i, x, calx)
+		}
+		if calx2 != 100*x {
+			t.Errorf("entry %d: x=%v cal.v2.x=%v (index-based friend)", i, x, calx2)
+		}
+	}
+}
+
+func TestTreeIndex(t *testing.T) {
+	const fname = "tree-index.root"
+	const evtmax = 100
+	createFlatTree(t, fname, evtmax)
+	defer os.Remove(fname)
+
+	f, err := croot.OpenFile(fname, "read", "croot flat file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+
+	// the pure-Go index first: the ROOT one stays attached to the tree.
+	for _, useROOT := range []bool{false, true} {
+		tree := f.GetTree("tree")
+		var e FlatEvent
+		tree.SetBranchAddress("i", &e.I)
+		tree.SetBranchAddress("y", &e.Y)
+
+		var n int
+		if useROOT {
+			n, err = tree.BuildIndex("i", "")
+		} else {
+			n, err = tree.BuildGoIndex("i", "")
+		}
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if n != evtmax {
+			t.Fatalf("expected %d indexed entries, got %d", evtmax, n)
+		}
+
+		for _, i := range []int64{42, 0, 99, 7} {
+			if entry := tree.GetEntryNumberWithIndex(i, 0); entry != i {
+				t.Errorf("root=%v: expected entry %d, got %d", useROOT, i, entry)
+			}
+			if tree.GetEntryWithIndex(i, 0) <= 0 {
+				t.Fatalf("root=%v: could not read entry with index %d", useROOT, i)
+			}
+			if e.I != i || e.Y != 2*float64(i) {
+				t.Errorf("root=%v: invalid values %+v for index %d", useROOT, e, i)
+			}
+		}
+
+		if entry := tree.GetEntryNumberWithIndex(1000, 0); entry >= 0 {
+			t.Errorf("root=%v: expected no entry, got %d", useROOT, entry)
+		}
+		if tree.GetEntryWithIndex(1000, 0) >= 0 {
+			t.Errorf("root=%v: expected an error for an invalid index", useROOT)
+		}
+	}
+}
+
+func TestSkim(t *testing.T) {
+	const fname = "tree-skim-in.root"
+	const oname = "tree-skim-out.root"
+	const evtmax = 100
+	createFlatTree(t, fname, evtmax)
+	defer os.Remove(fname)
+	defer os.Remove(oname)
+
+	f, err := croot.OpenFile(fname, "read", "croot flat file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+
+	o, err := croot.OpenFile(oname, "recreate", "croot skimmed file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	tree := f.GetTree("tree")
+	var i int64
+	tree.SetBranchAddress("i", &i)
+	n, err := croot.Skim(o, tree, []string{"i", "y"}, func(int64) bool { return i%4 == 0 })
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if n != evtmax/4 {
+		t.Fatalf("expected %d skimmed entries, got %d", evtmax/4, n)
+	}
+	o.Close("")
+
+	o, err = croot.OpenFile(oname, "read", "croot skimmed file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer o.Close("")
+
+	skim := o.GetTree("tree")
+	if skim == nil {
+		t.Fatalf("could not retrieve skimmed tree")
+	}
+	if skim.GetEntries() != n {
+		t.Fatalf("expected %d entries, got %d", n, skim.GetEntries())
+	}
+	if skim.GetBranch("x") != nil {
+		t.Fatalf("branch [x] should have been dropped")
+	}
+
+	var e FlatEvent
+	skim.SetBranchAddress("i", &e.I)
+	skim.SetBranchAddress("y", &e.Y)
+	for ientry := int64(0); ientry != n; ientry++ {
+		if skim.GetEntry(ientry, 1) <= 0 {
+			t.Fatalf("could not read entry %d", ientry)
+		}
+		if e.I != 4*ientry || e.Y != 2*float64(e.I) {
+			t.Errorf("entry %d: invalid values %+v", ientry, e)
+		}
+	}
+
+	// the cut is applied on a branch which is not copied over.
+	{
+		const xname = "tree-skim-x.root"
+		defer os.Remove(xname)
+		o, err := croot.OpenFile(xname, "recreate", "croot skimmed file", 1, 0)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		var x float64
+		tree.SetBranchAddress("x", &x)
+		n, err := croot.Skim(o, tree, []string{"i"}, func(int64) bool { return x < 10 })
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if n != 10 {
+			t.Fatalf("expected 10 skimmed entries, got %d", n)
+		}
+		o.Close("")
+
+		o, err = croot.OpenFile(xname, "read", "croot skimmed file", 1, 0)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		defer o.Close("")
+		skim := o.GetTree("tree")
+		if skim.GetEntries() != n || skim.GetBranch("x") != nil {
+			t.Fatalf("expected %d entries without branch [x], got %d", n, skim.GetEntries())
+		}
+		var i int64
+		skim.SetBranchAddress("i", &i)
+		for ientry := int64(0); ientry != n; ientry++ {
+			if skim.GetEntry(ientry, 1) <= 0 {
+				t.Fatalf("could not read entry %d", ientry)
+			}
+			if i != ientry {
+				t.Errorf("entry %d: expected i=%d, got %d", ientry, ientry, i)
+			}
+		}
+	}
+
+	// all the branches are active again on the input tree
+	if tree.GetEntry(1, 1) <= 0 {
+		t.Fatalf("could not read back the input tree")
+	}
+	if sel, err := tree.CopyTree("i<10"); err != nil {
+		t.Fatalf(err.Error())
+	} else if sel.GetEntries() != 10 || sel.GetBranch("x") == nil {
+		t.Errorf("CopyTree: expected 10 entries with all branches, got %d", sel.GetEntries())
+	}
+}
+
+func TestMergeFiles(t *testing.T) {
+	const evtmax = 100
+	inputs := []string{"tree-merge-1.root", "tree-merge-2.root"}
+	for _, fname := range inputs {
+		createFlatTree(t, fname, evtmax)
+		defer os.Remove(fname)
+	}
+
+	const oname = "tree-merge-out.root"
+	defer os.Remove(oname)
+	err := croot.MergeFiles(oname, inputs...)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	f, err := croot.OpenFile(oname, "read", "croot merged file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	keys := f.GetListOfKeys()
+	if len(keys) != 1 || keys[0].Name != "tree" || keys[0].ClassName != "TTree" {
+		t.Errorf("unexpected keys: %+v", keys)
+	}
+	tree := f.GetTree("tree")
+	if n := tree.GetEntries(); n != 2*evtmax {
+		t.Errorf("expected %d entries, got %d", 2*evtmax, n)
+	}
+	f.Close("")
+
+	// a merge into an existing file has to be forced
+	err = croot.MergeFiles(oname, inputs...)
+	if err == nil {
+		t.Fatalf("expected an error when overwriting [%s]", oname)
+	}
+
+	// a histogram named like the tree of the other inputs
+	const bad = "tree-merge-bad.root"
+	defer os.Remove(bad)
+	b, err := croot.OpenFile(bad, "recreate", "croot bad file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	h := croot.NewH1F("tree", "tree", 10, 0, 10)
+	h.Fill(1, 1)
+	b.Write("", 0, 0)
+	b.Close("")
+
+	opts := croot.DefaultMergeOptions
+	opts.Force = true
+	err = croot.MergeFilesWith(oname, opts, append(inputs, bad)...)
+	if err == nil {
+		t.Fatalf("expected a type mismatch error")
+	}
+
+	// a histogram and a tree with the same name, in a sub-directory
+	subs := []string{"tree-merge-sub-1.root", "tree-merge-sub-2.root"}
+	for i, fname := range subs {
+		defer os.Remove(fname)
+		f, err := croot.OpenFile(fname, "recreate", "croot sub-dir file", 1, 0)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		dir, err := f.Mkdir("dir", "a directory")
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if !dir.Cd("") {
+			t.Fatalf("could not cd into [dir]")
+		}
+		if i == 0 {
+			h := croot.NewH1F("obj", "obj", 10, 0, 10)
+			h.Fill(1, 1)
+		} else {
+			tree := croot.NewTree("obj", "obj", 32)
+			var x float64
+			_, err = tree.Branch2("x", &x, "x/D", 32000)
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+			_, err = tree.Fill()
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+		}
+		f.Write("", 0, 0)
+		f.Close("")
+	}
+	err = croot.MergeFilesWith(oname, opts, subs...)
+	if err == nil || !strings.Contains(err.Error(), "[dir/obj]") {
+		t.Fatalf("expected a type mismatch error for [dir/obj], got: %v", err)
+	}
+
+	// parallel merges, the number of inputs not being a multiple of the
+	// number of workers.
+	for _, test := range []struct {
+		ninputs  int
+		parallel int
+	}{
+		{5, 4},
+		{7, 3},
+	} {
+		var inputs []string
+		for i := 0; i < test.ninputs; i++ {
+			fname := fmt.Sprintf("tree-merge-par-%d.root", i)
+			createFlatTree(t, fname, evtmax)
+			defer os.Remove(fname)
+			inputs = append(inputs, fname)
+		}
+
+		opts := croot.DefaultMergeOptions
+		opts.Force = true
+		opts.Parallel = test.parallel
+		err = croot.MergeFilesWith(oname, opts, inputs...)
+		if err != nil {
+			t.Fatalf("%d inputs on %d workers: %v", test.ninputs, test.parallel, err)
+		}
+
+		f, err := croot.OpenFile(oname, "read", "croot merged file", 1, 0)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if n := f.GetTree("tree").GetEntries(); n != int64(test.ninputs*evtmax) {
+			t.Errorf("%d inputs on %d workers: expected %d entries, got %d",
+				test.ninputs, test.parallel, test.ninputs*evtmax, n)
+		}
+		f.Close("")
+	}
+}
+
+// readFlatTree reads the x branch of all the entries of the tree in fname
+// and returns the number of read calls issued on the file.
+func readFlatTree(tb testing.TB, fname string, cachesize int64) int {
+	f, err := croot.OpenFile(fname, "read", "croot flat file", 1, 0)
+	if err != nil {
+		tb.Fatalf(err.Error())
+	}
+	defer f.Close("")
+
+	tree := f.GetTree("tree")
+	tree.SetCacheSize(cachesize)
+	var x float64
+	tree.SetBranchAddress("x", &x)
+
+	n := f.GetReadCalls()
+	for i := int64(0); i != tree.GetEntries(); i++ {
+		if tree.GetEntry(i, 0) <= 0 {
+			tb.Fatalf("could not read entry %d", i)
+		}
+		if x != float64(i) {
+			tb.Fatalf("entry %d: invalid value %v", i, x)
+		}
+	}
+	return f.GetReadCalls() - n
+}
+
+func TestTreeCache(t *testing.T) {
+	const fname = "tree-cache.root"
+	const evtmax = 100000
+	createFlatTree(t, fname, evtmax)
+	defer os.Remove(fname)
+
+	nocache := readFlatTree(t, fname, 0)
+	cache := readFlatTree(t, fname, 10*1024*1024)
+	if cache >= nocache {
+		t.Errorf("expected fewer read calls with a cache (cache=%d, no-cache=%d)", cache, nocache)
+	}
+}
+
+func BenchmarkTreeCache(b *testing.B) {
+	const fname = "tree-cache-bench.root"
+	const evtmax = 100000
+	createFlatTree(b, fname, evtmax)
+	defer os.Remove(fname)
+
+	for _, bench := range []struct {
+		name      string
+		cachesize int64
+	}{
+		{"NoCache", 0},
+		{"Cache", 10 * 1024 * 1024},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			calls := 0
+			for i := 0; i < b.N; i++ {
+				calls += readFlatTree(b, fname, bench.cachesize)
+			}
+			b.ReportMetric(float64(calls)/float64(b.N), "readcalls/op")
+		})
+	}
+}
+
+func TestCompressionSettings(t *testing.T) {
+	const fname = "tree-compression.root"
+	const evtmax = 1000
+	defer os.Remove(fname)
+
+	fsettings := croot.CompressionSettings(croot.LZMA, 5)
+	bsettings := croot.CompressionSettings(croot.ZLIB, 1)
+	const bufsize = 4096
+	const autoflush = 100
+
+	f, err := croot.OpenFile(fname, "recreate", "croot compressed file", fsettings, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	tree := croot.NewTree("tree", "tree", 32)
+	tree.SetAutoFlush(autoflush)
+	tree.SetAutoSave(-1024 * 1024)
+	var e FlatEvent
+	bi, err := tree.Branch2("i", &e.I, "i/L", 32000)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	bi.SetBasketSize(bufsize)
+	// the basket sizes may be optimized at the first auto-flush: check the
+	// setting before filling.
+	if n := bi.GetBasketSize(); n != bufsize {
+		t.Errorf("expected basket size=%d, got %d", bufsize, n)
+	}
+	bx, err := tree.Branch2("x", &e.X, "x/D", 32000)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	bx.SetCompressionSettings(bsettings)
+
+	for i := int64(0); i != evtmax; i++ {
+		e.I = i
+		e.X = float64(i)
+		if _, err = tree.Fill(); err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+	f.Write("", 0, 0)
+	f.Close("")
+
+	f, err = croot.OpenFile(fname, "read", "croot compressed file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+
+	if alg, lvl := croot.CompressionAlgorithmOf(f.GetCompressionSettings()); alg != croot.LZMA || lvl != 5 {
+		t.Errorf("file: expected lzma/5, got %v/%d", alg, lvl)
+	}
+
+	tree = f.GetTree("tree")
+	if n := tree.GetAutoFlush(); n != autoflush {
+		t.Errorf("expected auto-flush=%d, got %d", autoflush, n)
+	}
+	if s := tree.GetBranch("x").GetCompressionSettings(); s != bsettings {
+		t.Errorf("expected branch compression settings=%d, got %d", bsettings, s)
+	}
+	if s := tree.GetBranch("i").GetCompressionSettings(); s != fsettings {
+		t.Errorf("expected branch compression settings=%d, got %d", fsettings, s)
+	}
+}
+
+func TestSizeSummary(t *testing.T) {
+	const fname = "tree-sizes.root"
+	const evtmax = 10000
+	createFlatTree(t, fname, evtmax)
+	defer os.Remove(fname)
+
+	f, err := croot.OpenFile(fname, "read", "croot flat file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+
+	tree := f.GetTree("tree")
+	sizes := croot.SizeSummary(tree)
+	if len(sizes) != 3 {
+		t.Fatalf("expected 3 branches, got %d", len(sizes))
+	}
+	for i, bs := range sizes {
+		if bs.Entries != evtmax {
+			t.Errorf("%s: expected %d entries, got %d", bs.Name, evtmax, bs.Entries)
+		}
+		if bs.ZipBytes <= 0 || bs.TotBytes <= 0 || bs.Baskets <= 0 {
+			t.Errorf("%s: invalid sizes %+v", bs.Name, bs)
+		}
+		if i > 0 && bs.ZipBytes > sizes[i-1].ZipBytes {
+			t.Errorf("sizes not sorted: %+v", sizes)
+		}
+	}
+
+	var buf bytes.Buffer
+	err = croot.WriteSizeSummary(&buf, tree)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if n := strings.Count(buf.String(), "\n"); n != len(sizes)+2 {
+		t.Errorf("expected %d lines, got %d:\n%s", len(sizes)+2, n, buf.String())
+	}
+
+	// the baskets of a split branch are those of its sub-branches
+	const sname = "tree-sizes-split.root"
+	createSplitTree(t, sname, evtmax)
+	defer os.Remove(sname)
+
+	sf, err := croot.OpenFile(sname, "read", "croot split file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer sf.Close("")
+
+	stree := sf.GetTree("tree")
+	sizes = croot.SizeSummary(stree)
+	if len(sizes) != 1 || sizes[0].Name != "evt" {
+		t.Fatalf("unexpected branches: %+v", sizes)
+	}
+	evt := stree.GetBranch("evt")
+	subs := evt.GetListOfBranches()
+	if len(subs) == 0 {
+		t.Fatalf("[evt] is not split")
+	}
+	var baskets func(b croot.Branch) int
+	baskets = func(b croot.Branch) int {
+		n := b.GetWriteBasket()
+		for _, sub := range b.GetListOfBranches() {
+			n += baskets(sub)
+		}
+		return n
+	}
+	if n := baskets(evt); sizes[0].Baskets != n || n < len(subs) {
+		t.Errorf("evt: expected %d baskets (at least %d), got %d", n, len(subs), sizes[0].Baskets)
+	}
+}
+
+func TestDirectory(t *testing.T) {
+	const fname = "dir.root"
+	defer os.Remove(fname)
+
+	f, err := croot.OpenFile(fname, "recreate", "croot dir file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	dir, err := f.Mkdir("dir", "a directory")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	sub, err := dir.Mkdir("sub", "a sub-directory")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if !sub.Cd("") {
+		t.Fatalf("could not cd into [dir/sub]")
+	}
+	h := croot.NewH1F("h", "h", 10, 0, 10)
+	h.Fill(1, 1)
+	f.Write("", 0, 0)
+	f.Close("")
+
+	f, err = croot.OpenFile(fname, "read", "croot dir file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+
+	keys := f.GetListOfKeys()
+	if len(keys) != 1 || keys[0].Name != "dir" || keys[0].ClassName != "TDirectoryFile" {
+		t.Fatalf("unexpected keys: %+v", keys)
+	}
+	dir, ok := f.Get("dir").(croot.Directory)
+	if !ok {
+		t.Fatalf("[dir] is not a directory")
+	}
+	keys = dir.GetListOfKeys()
+	if len(keys) != 1 || keys[0].Name != "sub" {
+		t.Fatalf("unexpected keys in [dir]: %+v", keys)
+	}
+	sub, ok = dir.Get("sub").(croot.Directory)
+	if !ok {
+		t.Fatalf("[dir/sub] is not a directory")
+	}
+	keys = sub.GetListOfKeys()
+	if len(keys) != 1 || keys[0].Name != "h" || keys[0].ClassName != "TH1F" || keys[0].Cycle != 1 {
+		t.Fatalf("unexpected keys in [dir/sub]: %+v", keys)
+	}
+	if keys[0].Nbytes <= 0 || keys[0].ObjLen <= 0 {
+		t.Errorf("invalid key sizes: %+v", keys[0])
+	}
+	if o := f.Get("dir/sub/h"); o == nil || o.ClassName() != "TH1F" {
+		t.Errorf("could not retrieve [dir/sub/h]")
+	}
+}
+
+func TestReader(t *testing.T) {
+	const fname = "tree-reader.root"
+	const evtmax = 10
+	createFlatTree(t, fname, evtmax)
+	defer os.Remove(fname)
+
+	f, err := croot.OpenFile(fname, "read", "croot flat file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+
+	tree := f.GetTree("tree")
+	r, err := croot.NewReader(tree)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer r.Close()
+
+	cols := r.Columns()
+	if len(cols) != 3 || len(r.Skipped()) != 0 {
+		t.Fatalf("expected 3 columns, got %d (skipped: %v)", len(cols), r.Skipped())
+	}
+	for i, want := range []struct {
+		name string
+		typ  reflect.Type
+	}{
+		{"i", reflect.TypeOf(int64(0))},
+		{"x", reflect.TypeOf(float64(0))},
+		{"y", reflect.TypeOf(float64(0))},
+	} {
+		if cols[i].Name != want.name || cols[i].Type != want.typ {
+			t.Errorf("column %d: expected %s (%v), got %s (%v)",
+				i, want.name, want.typ, cols[i].Name, cols[i].Type)
+		}
+	}
+
+	for i := int64(0); i != evtmax; i++ {
+		vals, err := r.Read(i)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		want := []interface{}{i, float64(i), 2 * float64(i)}
+		if !reflect.DeepEqual(vals, want) {
+			t.Errorf("entry %d: expected %v, got %v", i, want, vals)
+		}
+	}
+
+	ry, err := croot.NewReader(tree, "y")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(ry.Columns()) != 1 || ry.Columns()[0].Name != "y" {
+		t.Fatalf("expected a single [y] column, got %+v", ry.Columns())
+	}
+	vals, err := ry.Read(3)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if vals[0] != 6.0 {
+		t.Errorf("expected y=6, got %v", vals[0])
+	}
+	ry.Close()
+
+	if _, err = croot.NewReader(tree, "nosuchbranch"); err == nil {
+		t.Errorf("expected an error for an unknown branch")
+	}
+}
+
+func TestReaderArrays(t *testing.T) {
+	const fname = "tree-reader-arrays.root"
+	const evtmax = 10
+	createArrayTree(t, fname, evtmax)
+	defer os.Remove(fname)
+
+	f, err := croot.OpenFile(fname, "read", "croot array file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+
+	r, err := croot.NewReader(f.GetTree("tree"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer r.Close()
+
+	// the leaves of structs are flattened into "branch.leaf" columns.
+	cols := r.Columns()
+	wcols := []struct {
+		name string
+		typ  reflect.Type
+	}{
+		{"n", reflect.TypeOf(int32(0))},
+		{"fix", reflect.TypeOf([3]float64{})},
+		{"mat", reflect.TypeOf([6]float32{})},
+		{"v", reflect.TypeOf([]float32{})},
+		{"s.a", reflect.TypeOf(int32(0))},
+		{"s.b", reflect.TypeOf(float64(0))},
+		{"vec", reflect.TypeOf([]float64{})},
+	}
+	if len(cols) != len(wcols) || len(r.Skipped()) != 0 {
+		t.Fatalf("expected %d columns, got %d (skipped: %v)", len(wcols), len(cols), r.Skipped())
+	}
+	for i, want := range wcols {
+		if cols[i].Name != want.name || cols[i].Type != want.typ {
+			t.Errorf("column %d: expected %s (%v), got %s (%v)",
+				i, want.name, want.typ, cols[i].Name, cols[i].Type)
+		}
+	}
+
+	for i := int64(0); i != evtmax; i++ {
+		vals, err := r.Read(i)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		e := newArrayEvent(i)
+		want := []interface{}{
+			e.N, e.Fix, e.Mat, e.V, e.S.A, e.S.B,
+			append([]float64{}, e.Vec...),
+		}
+		if !reflect.DeepEqual(vals, want) {
+			t.Errorf("entry %d:\nexpected: %v\ngot:      %v", i, want, vals)
+		}
+	}
+
+	// selecting a counted array also reads its count.
+	rv, err := croot.NewReader(f.GetTree("tree"), "v")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer rv.Close()
+	vals, err := rv.Read(5)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if want := newArrayEvent(5).V; !reflect.DeepEqual(vals[0], want) {
+		t.Errorf("expected v=%v, got %v", want, vals[0])
+	}
+}
+
+func TestRow(t *testing.T) {
+	const fname = "tree-row.root"
+	const evtmax = 10
+	createFlatTree(t, fname, evtmax)
+	defer os.Remove(fname)
+
+	f, err := croot.OpenFile(fname, "read", "croot flat file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+
+	r, err := croot.NewReader(f.GetTree("tree"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer r.Close()
+
+	typ := r.Type()
+	if typ.NumField() != 3 {
+		t.Fatalf("expected 3 fields, got %d", typ.NumField())
+	}
+	for i, name := range []string{"i", "x", "y"} {
+		if tag := typ.Field(i).Tag.Get("croot"); tag != name {
+			t.Errorf("field %d: expected tag [%s], got [%s]", i, name, tag)
+		}
+	}
+
+	n := int64(0)
+	for {
+		row, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		if row.Entry() != n || row.Len() != 3 {
+			t.Fatalf("entry %d: invalid row (entry=%d, len=%d)", n, row.Entry(), row.Len())
+		}
+		i, err := row.Int("i")
+		if err != nil || i != n {
+			t.Errorf("entry %d: expected i=%d, got %d (err=%v)", n, n, i, err)
+		}
+		y, err := row.Float("y")
+		if err != nil || y != 2*float64(n) {
+			t.Errorf("entry %d: expected y=%v, got %v (err=%v)", n, 2*float64(n), y, err)
+		}
+		if x, ok := row.Get("x"); !ok || x != float64(n) {
+			t.Errorf("entry %d: expected x=%v, got %v", n, float64(n), x)
+		}
+		if _, err = row.String("x"); err == nil {
+			t.Errorf("entry %d: expected an error for a string accessor on [x]", n)
+		}
+		if _, err = row.Int("nosuchcolumn"); err == nil {
+			t.Errorf("entry %d: expected an error for an unknown column", n)
+		}
+		v := reflect.ValueOf(row.Struct()).Elem()
+		if v.Field(0).Int() != n || v.Field(2).Float() != 2*float64(n) {
+			t.Errorf("entry %d: invalid struct %+v", n, row.Struct())
+		}
+		n++
+	}
+	if n != evtmax {
+		t.Errorf("expected %d rows, got %d", evtmax, n)
+	}
+}
+
+func TestRowArrays(t *testing.T) {
+	const fname = "tree-row-arrays.root"
+	const evtmax = 10
+	createArrayTree(t, fname, evtmax)
+	defer os.Remove(fname)
+
+	f, err := croot.OpenFile(fname, "read", "croot array file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+
+	r, err := croot.NewReader(f.GetTree("tree"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer r.Close()
+
+	typ := r.Type()
+	for i, want := range []struct {
+		name string
+		tag  string
+	}{
+		{"N", "n"},
+		{"Fix", "fix"},
+		{"Mat", "mat"},
+		{"V", "v"},
+		{"SA", "s.a"},
+		{"SB", "s.b"},
+		{"Vec", "vec"},
+	} {
+		if f := typ.Field(i); f.Name != want.name || f.Tag.Get("croot") != want.tag {
+			t.Errorf("field %d: expected %s (tag [%s]), got %s (tag [%s])",
+				i, want.name, want.tag, f.Name, f.Tag.Get("croot"))
+		}
+	}
+
+	floats := func(v interface{}) []float64 {
+		rv := reflect.ValueOf(v)
+		out := make([]float64, rv.Len())
+		for i := range out {
+			out[i] = rv.Index(i).Float()
+		}
+		return out
+	}
+
+	n := int64(0)
+	for {
+		row, err := r.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		e := newArrayEvent(n)
+		for _, col := range []struct {
+			name string
+			want []float64
+		}{
+			{"fix", floats(e.Fix)},
+			{"mat", floats(e.Mat)},
+			{"v", floats(e.V)},
+			{"vec", floats(e.Vec)},
+		} {
+			got, err := row.Floats(col.name)
+			if err != nil || !reflect.DeepEqual(got, col.want) {
+				t.Errorf("entry %d: expected %s=%v, got %v (err=%v)", n, col.name, col.want, got, err)
+			}
+		}
+		if v, ok := row.Get("v"); !ok || !reflect.DeepEqual(v, e.V) {
+			t.Errorf("entry %d: expected v=%v, got %v", n, e.V, v)
+		}
+		if a, err := row.Int("s.a"); err != nil || a != int64(e.S.A) {
+			t.Errorf("entry %d: expected s.a=%d, got %d (err=%v)", n, e.S.A, a, err)
+		}
+		if _, err = row.Ints("fix"); err == nil {
+			t.Errorf("entry %d: expected an error for an integer accessor on [fix]", n)
+		}
+		if _, err = row.Float("fix"); err == nil {
+			t.Errorf("entry %d: expected an error for a scalar accessor on [fix]", n)
+		}
+
+		v := reflect.ValueOf(row.Struct()).Elem()
+		if fix := v.FieldByName("Fix").Interface(); fix != e.Fix {
+			t.Errorf("entry %d: expected Fix=%v, got %v", n, e.Fix, fix)
+		}
+		if vec := v.FieldByName("Vec"); vec.Len() != len(e.Vec) {
+			t.Errorf("entry %d: expected %d elements in Vec, got %d", n, len(e.Vec), vec.Len())
+		}
+		n++
+	}
+	if n != evtmax {
+		t.Errorf("expected %d rows, got %d", evtmax, n)
+	}
+}
+
+func TestWriter(t *testing.T) {
+	const fname = "tree-writer.root"
+	const evtmax = 10
+	defer os.Remove(fname)
+
+	f, err := croot.OpenFile(fname, "recreate", "croot writer file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	tree := croot.NewTree("tree", "tree", 32)
+	w, err := croot.NewWriter(tree, []croot.ColumnSpec{
+		{Name: "n", Kind: reflect.Int32},
+		{Name: "e", Kind: reflect.Float64},
+		{Name: "ok", Kind: reflect.Bool},
+		{Name: "p", Kind: reflect.Float32, Len: 3},
+	}, 32000)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for i := 0; i != evtmax; i++ {
+		var err error
+		if i%2 == 0 {
+			_, err = w.Write([]interface{}{i, float64(i) * 0.5, true, []float64{1, 2, float64(i)}})
+		} else {
+			_, err = w.WriteMap(map[string]interface{}{
+				"n": int64(i),
+				"e": float32(i) * 0.5,
+				"p": [3]float32{1, 2, float32(i)},
+			})
+		}
+		if err != nil {
+			t.Fatalf("entry %d: %v", i, err)
+		}
+	}
+
+	if _, err = w.Write([]interface{}{1, 2.0}); err == nil {
+		t.Errorf("expected an error for a short row")
+	}
+	if _, err = w.WriteMap(map[string]interface{}{"nosuchcolumn": 1}); err == nil {
+		t.Errorf("expected an error for an unknown column")
+	}
+	if _, err = w.Write([]interface{}{1, "2", true, []float64{1, 2, 3}}); err == nil {
+		t.Errorf("expected an error for a string value")
+	}
+	for _, row := range [][]interface{}{
+		{1.5, 2.0, true, []float64{1, 2, 3}},
+		{int64(1) << 40, 2.0, true, []float64{1, 2, 3}},
+		{uint64(math.MaxUint64), 2.0, true, []float64{1, 2, 3}},
+		{math.NaN(), 2.0, true, []float64{1, 2, 3}},
+		{math.Inf(1), 2.0, true, []float64{1, 2, 3}},
+		{1, 2.0, true, []float64{1, 2, 1e300}},
+	} {
+		if _, err = w.Write(row); err == nil {
+			t.Errorf("expected an error for a lossy conversion of %v", row)
+		}
+	}
+	if _, err = croot.NewWriter(tree, []croot.ColumnSpec{{Name: "c", Kind: reflect.Complex128}}, 32000); err == nil {
+		t.Errorf("expected an error for an unsupported kind")
+	}
+
+	f.Write("", 0, 0)
+	f.Close("")
+
+	f, err = croot.OpenFile(fname, "read", "croot writer file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+
+	r, err := croot.NewReader(f.GetTree("tree"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer r.Close()
+
+	if n := f.GetTree("tree").GetEntries(); n != evtmax {
+		t.Fatalf("expected %d entries, got %d", evtmax, n)
+	}
+	for i := int64(0); i != evtmax; i++ {
+		vals, err := r.Read(i)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		want := []interface{}{
+			int32(i), float64(i) * 0.5, i%2 == 0, [3]float32{1, 2, float32(i)},
+		}
+		if !reflect.DeepEqual(vals, want) {
+			t.Errorf("entry %d: expected %v, got %v", i, want, vals)
+		}
+	}
+}
+
+func TestExport(t *testing.T) {
+	const fname = "tree-export.root"
+	const evtmax = 10
+	createFlatTree(t, fname, evtmax)
+	defer os.Remove(fname)
+
+	f, err := croot.OpenFile(fname, "read", "croot flat file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+	tree := f.GetTree("tree")
+
+	buf := new(bytes.Buffer)
+	n, err := croot.ExportCSV(buf, tree, croot.ExportOptions{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if n != evtmax || len(lines) != evtmax+1 {
+		t.Fatalf("expected %d entries, got %d (%d lines)", evtmax, n, len(lines))
+	}
+	if lines[0] != "i,x,y" || lines[4] != "3,3,6" {
+		t.Errorf("invalid CSV output:\n%s", buf.String())
+	}
+
+	buf.Reset()
+	n, err = croot.ExportNDJSON(buf, tree, croot.ExportOptions{
+		Branches:  []string{"y"},
+		Selection: "i>=5",
+		First:     2,
+		N:         6,
+	})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	want := "{\"y\":10}\n{\"y\":12}\n{\"y\":14}\n"
+	if n != 3 || buf.String() != want {
+		t.Errorf("expected %d entries:\n%s\ngot %d entries:\n%s", 3, want, n, buf.String())
+	}
+
+	if _, err = croot.ExportCSV(buf, tree, croot.ExportOptions{Branches: []string{"nosuchbranch"}}); err == nil {
+		t.Errorf("expected an error for an unknown branch")
+	}
+}
+
+func TestExportArrays(t *testing.T) {
+	const fname = "tree-export-arrays.root"
+	const evtmax = 10
+	createArrayTree(t, fname, evtmax)
+	defer os.Remove(fname)
+
+	f, err := croot.OpenFile(fname, "read", "croot array file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+	tree := f.GetTree("tree")
+
+	buf := new(bytes.Buffer)
+	n, err := croot.ExportCSV(buf, tree, croot.ExportOptions{First: 2, N: 1})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	want := "n,fix[0],fix[1],fix[2],mat[0],mat[1],mat[2],mat[3],mat[4],mat[5],v,s.a,s.b,vec\n" +
+		"3,20,21,22,2,2.1,2.2,2.3,2.4,2.5,2 3 4,2,2.5,-2 -3\n"
+	if n != 1 || buf.String() != want {
+		t.Errorf("expected:\n%s\ngot %d entries:\n%s", want, n, buf.String())
+	}
+
+	buf.Reset()
+	n, err = croot.ExportCSV(buf, tree, croot.ExportOptions{
+		Branches: []string{"n", "fix", "v"},
+		First:    1,
+		N:        2,
+		Explode:  true,
+	})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	want = "n,fix,v\n" +
+		"2,10,1\n2,11,2\n2,12,\n" +
+		"3,20,2\n3,21,3\n3,22,4\n"
+	if n != 2 || buf.String() != want {
+		t.Errorf("expected:\n%s\ngot %d entries:\n%s", want, n, buf.String())
+	}
+
+	// the selection reads a counted array, neither it nor its count being
+	// exported.
+	buf.Reset()
+	n, err = croot.ExportNDJSON(buf, tree, croot.ExportOptions{
+		Branches:  []string{"fix"},
+		Selection: "v[0]>7",
+	})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	want = "{\"fix\":[80,81,82]}\n{\"fix\":[90,91,92]}\n"
+	if n != 2 || buf.String() != want {
+		t.Errorf("expected:\n%s\ngot %d entries:\n%s", want, n, buf.String())
+	}
+
+	// NaNs and infinities
+	tree = croot.NewTree("nan", "nan", 32)
+	w, err := croot.NewWriter(tree, []croot.ColumnSpec{
+		{Name: "x", Kind: reflect.Float64},
+		{Name: "p", Kind: reflect.Float32, Len: 2},
+	}, 32000)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	for _, row := range [][]interface{}{
+		{math.NaN(), []float64{math.Inf(1), 1}},
+		{math.Inf(-1), []float64{1, math.NaN()}},
+	} {
+		if _, err = w.Write(row); err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+	buf.Reset()
+	n, err = croot.ExportNDJSON(buf, tree, croot.ExportOptions{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	want = "{\"x\":\"NaN\",\"p\":[\"+Inf\",1]}\n{\"x\":\"-Inf\",\"p\":[1,\"NaN\"]}\n"
+	if n != 2 || buf.String() != want {
+		t.Errorf("expected:\n%s\ngot %d entries:\n%s", want, n, buf.String())
+	}
+}
+
+func TestImportCSV(t *testing.T) {
+	const fname = "tree-import.root"
+	defer os.Remove(fname)
+
+	const data = `run,energy (GeV),name,flag,2nd
+1,0.5,a,1,10
+2,,bb,0,20
+3,2,"c,c",1,
+`
+
+	f, err := croot.OpenFile(fname, "recreate", "croot import file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	tree := croot.NewTree("tree", "tree", 32)
+	n, err := croot.ImportCSV(strings.NewReader(data), tree, croot.ImportOptions{
+		Types: map[string]reflect.Kind{"flag": reflect.Bool},
+	})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if n != 3 {
+		t.Fatalf("expected 3 records, got %d", n)
+	}
+	f.Write("", 0, 0)
+	f.Close("")
+
+	f, err = croot.OpenFile(fname, "read", "croot import file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+
+	r, err := croot.NewReader(f.GetTree("tree"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer r.Close()
+
+	var names []string
+	for _, col := range r.Columns() {
+		names = append(names, col.Name)
+	}
+	if want := []string{"run", "energy__GeV_", "name", "flag", "_2nd"}; !reflect.DeepEqual(names, want) {
+		t.Fatalf("expected columns %v, got %v", want, names)
+	}
+
+	vals, err := r.Read(2)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	want := []interface{}{int64(3), float64(2), "c,c", true, int64(0)}
+	if !reflect.DeepEqual(vals, want) {
+		t.Errorf("expected %v, got %v", want, vals)
+	}
+	vals, err = r.Read(1)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if e, ok := vals[1].(float64); !ok || !math.IsNaN(e) {
+		t.Errorf("expected a NaN energy for an empty value, got %v", vals[1])
+	}
+
+	// suffixed names of duplicates are not already in use
+	dup := croot.NewTree("dup", "dup", 32)
+	_, err = croot.ImportCSV(strings.NewReader("a_1,a,a\n1,2,3\n"), dup, croot.ImportOptions{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	rd, err := croot.NewReader(dup)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	names = names[:0]
+	for _, col := range rd.Columns() {
+		names = append(names, col.Name)
+	}
+	if want := []string{"a_1", "a", "a_2"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("expected columns %v, got %v", want, names)
+	}
+	vals, err = rd.Read(0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if want := []interface{}{int64(1), int64(2), int64(3)}; !reflect.DeepEqual(vals, want) {
+		t.Errorf("expected %v, got %v", want, vals)
+	}
+	rd.Close()
+
+	_, err = croot.ImportCSV(strings.NewReader("x\n1\n2\nabc\n"), croot.NewTree("bad", "bad", 32), croot.ImportOptions{Sample: 2})
+	if err == nil {
+		t.Errorf("expected an error for a non-integer value")
+	}
+}
+
+func TestExportNPZ(t *testing.T) {
+	const fname = "tree-npz.root"
+	const evtmax = 10
+	createFlatTree(t, fname, evtmax)
+	defer os.Remove(fname)
+
+	f, err := croot.OpenFile(fname, "read", "croot flat file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+
+	buf := new(bytes.Buffer)
+	n, err := croot.ExportNPZ(buf, f.GetTree("tree"), croot.ExportOptions{
+		Branches:  []string{"i", "y"},
+		Selection: "i%2==0",
+	})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if n != evtmax/2 {
+		t.Fatalf("expected %d entries, got %d", evtmax/2, n)
+	}
+
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(z.File) != 2 || z.File[0].Name != "i.npy" || z.File[1].Name != "y.npy" {
+		t.Fatalf("invalid npz content: %v", z.File)
+	}
+
+	r, err := z.File[1].Open()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	data, err := ioutil.ReadAll(r)
+	r.Close()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if !bytes.HasPrefix(data, []byte("\x93NUMPY\x01\x00")) {
+		t.Fatalf("invalid npy magic: %q", data[:8])
+	}
+	hlen := int(binary.LittleEndian.Uint16(data[8:10]))
+	if (10+hlen)%64 != 0 {
+		t.Errorf("npy data is not aligned (header length: %d)", hlen)
+	}
+	hdr := string(data[10 : 10+hlen])
+	if !strings.Contains(hdr, "'descr': '<f8'") || !strings.Contains(hdr, "'shape': (5,)") {
+		t.Errorf("invalid npy header: %q", hdr)
+	}
+	ys := make([]float64, n)
+	err = binary.Read(bytes.NewReader(data[10+hlen:]), binary.LittleEndian, ys)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if want := []float64{0, 4, 8, 12, 16}; !reflect.DeepEqual(ys, want) {
+		t.Errorf("expected y=%v, got %v", want, ys)
+	}
+}
+
+func TestExportNPZArrays(t *testing.T) {
+	const fname = "tree-npz-arrays.root"
+	const evtmax = 10
+	createArrayTree(t, fname, evtmax)
+	defer os.Remove(fname)
+
+	f, err := croot.OpenFile(fname, "read", "croot array file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+
+	buf := new(bytes.Buffer)
+	n, err := croot.ExportNPZ(buf, f.GetTree("tree"), croot.ExportOptions{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if n != evtmax {
+		t.Fatalf("expected %d entries, got %d", evtmax, n)
+	}
+
+	z, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	// expected content of the arrays
+	var (
+		fix     []float64
+		mat     []float32
+		voffs   = []int64{0}
+		vvals   []float32
+		vecoffs = []int64{0}
+		vecvals []float64
+	)
+	for i := int64(0); i != evtmax; i++ {
+		e := newArrayEvent(i)
+		fix = append(fix, e.Fix[:]...)
+		mat = append(mat, e.Mat[:]...)
+		vvals = append(vvals, e.V...)
+		voffs = append(voffs, int64(len(vvals)))
+		vecvals = append(vecvals, e.Vec...)
+		vecoffs = append(vecoffs, int64(len(vecvals)))
+	}
+
+	for i, want := range []struct {
+		name  string
+		descr string
+		shape string
+		data  interface{}
+	}{
+		{"n.npy", "<i4", "(10,)", nil},
+		{"fix.npy", "<f8", "(10, 3)", fix},
+		{"mat.npy", "<f4", "(10, 6)", mat},
+		{"v.offsets.npy", "<i8", "(11,)", voffs},
+		{"v.values.npy", "<f4", fmt.Sprintf("(%d,)", len(vvals)), vvals},
+		{"s.a.npy", "<i4", "(10,)", nil},
+		{"s.b.npy", "<f8", "(10,)", nil},
+		{"vec.offsets.npy", "<i8", "(11,)", vecoffs},
+		{"vec.values.npy", "<f8", fmt.Sprintf("(%d,)", len(vecvals)), vecvals},
+	} {
+		if i >= len(z.File) || z.File[i].Name != want.name {
+			t.Fatalf("file %d: expected [%s] (content: %v)", i, want.name, z.File)
+		}
+		r, err := z.File[i].Open()
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		data, err := ioutil.ReadAll(r)
+		r.Close()
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		hlen := int(binary.LittleEndian.Uint16(data[8:10]))
+		hdr := string(data[10 : 10+hlen])
+		if !strings.Contains(hdr, "'descr': '"+want.descr+"'") || !strings.Contains(hdr, "'shape': "+want.shape) {
+			t.Errorf("%s: expected %s%s, got header %q", want.name, want.descr, want.shape, hdr)
+		}
+		if want.data == nil {
+			continue
+		}
+		size := reflect.ValueOf(want.data).Len()
+		got := reflect.MakeSlice(reflect.TypeOf(want.data), size, size).Interface()
+		err = binary.Read(bytes.NewReader(data[10+hlen:]), binary.LittleEndian, got)
+		if err != nil {
+			t.Fatalf("%s: %v", want.name, err)
+		}
+		if !reflect.DeepEqual(got, want.data) {
+			t.Errorf("%s: expected %v, got %v", want.name, want.data, got)
+		}
+	}
+	if len(z.File) != 9 {
+		t.Errorf("expected 9 arrays, got %d", len(z.File))
+	}
+}
+
+// fbTable is a FlatBuffers table of the metadata of an Arrow file
+type fbTable struct {
+	buf []byte
+	pos int
+}
+
+// fbRoot returns the root table of the FlatBuffers buf.
+func fbRoot(buf []byte) fbTable {
+	return fbTable{buf, int(binary.LittleEndian.Uint32(buf))}
+}
+
+// field returns the position of the i-th field of the table, 0 if absent.
+func (t fbTable) field(i int) int {
+	vtable := t.pos - int(int32(binary.LittleEndian.Uint32(t.buf[t.pos:])))
+	if 4+2*i >= int(binary.LittleEndian.Uint16(t.buf[vtable:])) {
+		return 0
+	}
+	off := int(binary.LittleEndian.Uint16(t.buf[vtable+4+2*i:]))
+	if off == 0 {
+		return 0
+	}
+	return t.pos + off
+}
+
+// uint returns the value of the i-th field, an unsigned integer of the
+// given size in bytes (0 if absent).
+func (t fbTable) uint(i, size int) uint64 {
+	p := t.field(i)
+	if p == 0 {
+		return 0
+	}
+	switch size {
+	case 1:
+		return uint64(t.buf[p])
+	case 2:
+		return uint64(binary.LittleEndian.Uint16(t.buf[p:]))
+	case 4:
+		return uint64(binary.LittleEndian.Uint32(t.buf[p:]))
+	}
+	return binary.LittleEndian.Uint64(t.buf[p:])
+}
+
+// deref returns the position of the object referenced by the i-th field.
+func (t fbTable) deref(i int) int {
+	p := t.field(i)
+	if p == 0 {
+		return 0
+	}
+	return p + int(binary.LittleEndian.Uint32(t.buf[p:]))
+}
+
+func (t fbTable) table(i int) fbTable {
+	return fbTable{t.buf, t.deref(i)}
+}
+
+func (t fbTable) tables(i int) []fbTable {
+	p := t.deref(i)
+	if p == 0 {
+		return nil
+	}
+	tables := make([]fbTable, binary.LittleEndian.Uint32(t.buf[p:]))
+	for j := range tables {
+		at := p + 4 + 4*j
+		tables[j] = fbTable{t.buf, at + int(binary.LittleEndian.Uint32(t.buf[at:]))}
+	}
+	return tables
+}
+
+func (t fbTable) str(i int) string {
+	p := t.deref(i)
+	n := int(binary.LittleEndian.Uint32(t.buf[p:]))
+	return string(t.buf[p+4 : p+4+n])
+}
+
+// structs returns the elements of the vector of structs of the given size
+// referenced by the i-th field.
+func (t fbTable) structs(i, size int) [][]byte {
+	p := t.deref(i)
+	if p == 0 {
+		return nil
+	}
+	elems := make([][]byte, binary.LittleEndian.Uint32(t.buf[p:]))
+	for j := range elems {
+		at := p + 4 + size*j
+		elems[j] = t.buf[at : at+size]
+	}
+	return elems
+}
+
+// arrowField describes an Arrow Field table ("name:Type<children>").
+func arrowField(f fbTable) string {
+	typ := f.table(3)
+	var str string
+	switch ttype := f.uint(2, 1); ttype {
+	case 2:
+		str = fmt.Sprintf("Int%d", typ.uint(0, 4))
+		if typ.uint(1, 1) == 0 {
+			str = "U" + str
+		}
+	case 3:
+		str = []string{"Float16", "Float32", "Float64"}[typ.uint(0, 2)]
+	case 5:
+		str = "Utf8"
+	case 6:
+		str = "Bool"
+	case 12:
+		str = "List"
+	case 13:
+		str = "Struct"
+	case 16:
+		str = fmt.Sprintf("FixedSizeList[%d]", typ.uint(0, 4))
+	default:
+		str = fmt.Sprintf("Type(%d)", ttype)
+	}
+	if children := f.tables(5); len(children) > 0 {
+		names := make([]string, len(children))
+		for i, c := range children {
+			names[i] = arrowField(c)
+		}
+		str += "<" + strings.Join(names, ",") + ">"
+	}
+	return f.str(0) + ":" + str
+}
+
+// readArrow decodes the schema (as described by arrowField) and the
+// lengths of the field nodes of the record batches of an Arrow file.
+func readArrow(t *testing.T, data []byte) (fields []string, nodes [][]int64) {
+	if !bytes.HasPrefix(data, []byte("ARROW1\x00\x00")) || !bytes.HasSuffix(data, []byte("ARROW1")) {
+		t.Fatalf("invalid arrow magic")
+	}
+	flen := int(binary.LittleEndian.Uint32(data[len(data)-10:]))
+	if flen <= 0 || flen > len(data)-18 {
+		t.Fatalf("invalid arrow footer length (%d)", flen)
+	}
+	footer := fbRoot(data[len(data)-10-flen : len(data)-10])
+	if v := footer.uint(0, 2); v != 4 {
+		t.Errorf("expected metadata version V5 (4), got %d", v)
+	}
+	for _, f := range footer.table(1).tables(1) {
+		fields = append(fields, arrowField(f))
+	}
+
+	for _, block := range footer.structs(3, 24) {
+		offset := int(binary.LittleEndian.Uint64(block[0:]))
+		if binary.LittleEndian.Uint32(data[offset:]) != 0xffffffff {
+			t.Fatalf("invalid message at offset %d", offset)
+		}
+		msg := fbRoot(data[offset+8:])
+		if htype := msg.uint(1, 1); htype != 3 {
+			t.Fatalf("expected a RecordBatch message, got type %d", htype)
+		}
+		batch := msg.table(2)
+		var lengths []int64
+		for _, node := range batch.structs(1, 16) {
+			lengths = append(lengths, int64(binary.LittleEndian.Uint64(node)))
+		}
+		if n := int64(batch.uint(0, 8)); len(lengths) == 0 || n != lengths[0] {
+			t.Errorf("record batch of %d rows, first node of %v", n, lengths)
+		}
+		nodes = append(nodes, lengths)
+	}
+	return fields, nodes
+}
+
+func TestExportArrow(t *testing.T) {
+	const fname = "tree-arrow.root"
+	const evtmax = 10
+	createFlatTree(t, fname, evtmax)
+	defer os.Remove(fname)
+
+	f, err := croot.OpenFile(fname, "read", "croot flat file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+
+	buf := new(bytes.Buffer)
+	n, err := croot.ExportArrow(buf, f.GetTree("tree"), croot.ExportOptions{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if n != evtmax {
+		t.Fatalf("expected %d entries, got %d", evtmax, n)
+	}
+
+	data := buf.Bytes()
+	fields, nodes := readArrow(t, data)
+	if want := []string{"i:Int64", "x:Float64", "y:Float64"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("expected schema %v, got %v", want, fields)
+	}
+	if want := [][]int64{{10, 10, 10}}; !reflect.DeepEqual(nodes, want) {
+		t.Errorf("expected field nodes %v, got %v", want, nodes)
+	}
+
+	// the values of the x column (float64: 0, 1, ..., 9) are stored in the
+	// body of the record batch.
+	xs := make([]byte, 8*evtmax)
+	for i := 0; i < evtmax; i++ {
+		binary.LittleEndian.PutUint64(xs[8*i:], math.Float64bits(float64(i)))
+	}
+	if !bytes.Contains(data, xs) {
+		t.Errorf("could not find the values of the x column")
+	}
+}
+
+func TestExportArrowArrays(t *testing.T) {
+	const fname = "tree-arrow-arrays.root"
+	const evtmax = 10
+	createArrayTree(t, fname, evtmax)
+	defer os.Remove(fname)
+
+	f, err := croot.OpenFile(fname, "read", "croot array file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+
+	buf := new(bytes.Buffer)
+	n, err := croot.ExportArrow(buf, f.GetTree("tree"), croot.ExportOptions{})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if n != evtmax {
+		t.Fatalf("expected %d entries, got %d", evtmax, n)
+	}
+
+	fields, nodes := readArrow(t, buf.Bytes())
+	want := []string{
+		"n:Int32",
+		"fix:FixedSizeList[3]<item:Float64>",
+		"mat:FixedSizeList[6]<item:Float32>",
+		"v:List<item:Float32>",
+		"s:Struct<a:Int32,b:Float64>",
+		"vec:List<item:Float64>",
+	}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("expected schema:\n%v\ngot:\n%v", want, fields)
+	}
+
+	// field nodes, in depth-first order: the lengths of the children of
+	// lists are the total number of elements.
+	nv, nvec := 0, 0
+	for i := int64(0); i != evtmax; i++ {
+		e := newArrayEvent(i)
+		nv += len(e.V)
+		nvec += len(e.Vec)
+	}
+	wnodes := [][]int64{{
+		evtmax,
+		evtmax, 3 * evtmax,
+		evtmax, 6 * evtmax,
+		evtmax, int64(nv),
+		evtmax, evtmax, evtmax,
+		evtmax, int64(nvec),
+	}}
+	if !reflect.DeepEqual(nodes, wnodes) {
+		t.Errorf("expected field nodes %v, got %v", wnodes, nodes)
+	}
+}
+
+func TestSetBranchAddressArrays(t *testing.T) {
+	const fname = "tree-arrays.root"
+	const evtmax = 10
+	defer os.Remove(fname)
+
+	f, err := croot.OpenFile(fname, "recreate", "croot arrays file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	tree := croot.NewTree("tree", "tree", 32)
+	w, err := croot.NewWriter(tree, []croot.ColumnSpec{
+		{Name: "p", Kind: reflect.Float32, Len: 3},
+		{Name: "name", Kind: reflect.String},
+	}, 32000)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	for i := 0; i != evtmax; i++ {
+		_, err = w.Write([]interface{}{[]float32{1, 2, float32(i)}, fmt.Sprintf("evt-%d", i)})
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+	f.Write("", 0, 0)
+	f.Close("")
+
+	f, err = croot.OpenFile(fname, "read", "croot arrays file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+	tree = f.GetTree("tree")
+
+	var p [3]float32
+	var name string
+	if o := tree.SetBranchAddress("p", &p); o < 0 {
+		t.Fatalf("could not bind branch [p] (got %d)", o)
+	}
+	if o := tree.SetBranchAddress("name", &name); o < 0 {
+		t.Fatalf("could not bind branch [name] (got %d)", o)
+	}
+	var bad []float64
+	if o := tree.SetBranchAddress("p", &bad); o != -2 {
+		t.Errorf("expected a type mismatch (-2) binding [p] to a []float64, got %d", o)
+	}
+	if o := tree.SetBranchAddress("nosuchbranch", &bad); o != -5 {
+		t.Errorf("expected a missing branch (-5) binding [nosuchbranch], got %d", o)
+	}
+	tree.SetBranchAddress("p", &p)
+
+	for i := int64(0); i != evtmax; i++ {
+		if tree.GetEntry(i, 1) <= 0 {
+			t.Fatalf("could not read entry %d", i)
+		}
+		if want := [3]float32{1, 2, float32(i)}; p != want {
+			t.Errorf("entry %d: expected p=%v, got %v", i, want, p)
+		}
+		if want := fmt.Sprintf("evt-%d", i); name != want {
+			t.Errorf("entry %d: expected name=%q, got %q", i, want, name)
+		}
+	}
+
+	typ, err := croot.LeafType(tree.GetLeaf("p"))
+	if err != nil || typ != reflect.TypeOf(p) {
+		t.Errorf("expected leaf type %T, got %v (err=%v)", p, typ, err)
+	}
+
+	// a branch with several leaves is a type mismatch, not a missing branch.
+	const sname = "tree-arrays-struct.root"
+	createArrayTree(t, sname, 1)
+	defer os.Remove(sname)
+	sf, err := croot.OpenFile(sname, "read", "croot array file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer sf.Close("")
+	var vals []float64
+	if o := sf.GetTree("tree").SetBranchAddress("s", &vals); o != -2 {
+		t.Errorf("expected a type mismatch (-2) binding [s] to a []float64, got %d", o)
+	}
+}
+
+func TestSetBranchAddressLeaflist(t *testing.T) {
+	const fname = "tree-leaflist.root"
+	const evtmax = 10
+	createArrayTree(t, fname, evtmax)
+	defer os.Remove(fname)
+
+	f, err := croot.OpenFile(fname, "read", "croot array file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+	tree := f.GetTree("tree")
+
+	// the leaves of "a/I:b/D" are packed: b is at offset 4, not 8.
+	var s struct {
+		A int32
+		B float64
+	}
+	if o := tree.SetBranchAddress("s", &s); o < 0 {
+		t.Fatalf("could not set branch address of [s] (rc=%d)", o)
+	}
+	for i := int64(0); i != evtmax; i++ {
+		if tree.GetEntry(i, 1) <= 0 {
+			t.Fatalf("could not read entry %d", i)
+		}
+		if want := newArrayEvent(i).S; s.A != want.A || s.B != want.B {
+			t.Errorf("entry %d: expected s=%+v, got %+v", i, want, s)
+		}
+	}
+
+	for _, test := range []struct {
+		name string
+		ptr  interface{}
+	}{
+		{"one field", &struct{ A int32 }{}},
+		{"swapped fields", &struct {
+			B float64
+			A int32
+		}{}},
+		{"wrong type", &struct {
+			A int64
+			B float64
+		}{}},
+	} {
+		if o := tree.SetBranchAddress("s", test.ptr); o != -2 {
+			t.Errorf("%s: expected a type mismatch (-2), got %d", test.name, o)
+		}
+	}
+}
+
+func TestBranchValues(t *testing.T) {
+	const fname = "tree-values.root"
+	const evtmax = 10
+	defer os.Remove(fname)
+
+	f, err := croot.OpenFile(fname, "recreate", "croot values file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	tree := croot.NewTree("tree", "tree", 32)
+
+	var evt struct {
+		N    int32
+		V    []float32
+		Name string
+		S    struct {
+			A  int32
+			B  float64
+			Ok bool
+		}
+		Vec []float64
+		Str string
+	}
+	for _, br := range []struct {
+		name     string
+		addr     interface{}
+		leaflist string
+	}{
+		{"n", &evt.N, "n/I"},
+		{"v", &evt.V, "v[n]/F"},
+		{"name", &evt.Name, "name/C"},
+		{"s", &evt.S, "a/I:b/D:ok/O"},
+	} {
+		_, err = tree.Branch2(br.name, br.addr, br.leaflist, 32000)
+		if err != nil {
+			t.Fatalf("branch [%s]: %v", br.name, err)
+		}
+	}
+	_, err = tree.Branch("vec", &evt.Vec, 32000, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = tree.Branch("str", &evt.Str, 32000, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	var bad []int
+	if _, err = tree.Branch2("bad", &bad, "bad[n]/L", 32000); err == nil {
+		t.Errorf("expected an error creating a branch of []int")
+	}
+
+	want := make([][]interface{}, evtmax)
+	for i := 0; i != evtmax; i++ {
+		evt.V = evt.V[:0]
+		evt.Vec = evt.Vec[:0]
+		for j := 0; j <= i%3; j++ {
+			evt.V = append(evt.V, float32(i+j))
+			evt.Vec = append(evt.Vec, float64(-i-j))
+		}
+		evt.N = int32(len(evt.V))
+		evt.Name = strings.Repeat("x", 100*i)
+		evt.S.A = int32(i)
+		evt.S.B = float64(i) + 0.5
+		evt.S.Ok = i%2 == 0
+		evt.Str = fmt.Sprintf("evt-%d", i)
+		_, err = tree.Fill()
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		want[i] = []interface{}{
+			evt.N, append([]float32(nil), evt.V...), evt.Name,
+			evt.S.A, evt.S.B, evt.S.Ok,
+			append([]float64(nil), evt.Vec...), evt.Str,
+		}
+	}
+	f.Write("", 0, 0)
+	f.Close("")
+
+	f, err = croot.OpenFile(fname, "read", "croot values file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+
+	r, err := croot.NewReader(f.GetTree("tree"))
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer r.Close()
+
+	for i := 0; i != evtmax; i++ {
+		vals, err := r.Read(int64(i))
+		if err != nil {
+			t.Fatalf("entry %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(vals, want[i]) {
+			t.Errorf("entry %d:\nexpected: %v\ngot:      %v", i, want[i], vals)
+		}
+	}
+}
+
+func TestBranchValuesDelete(t *testing.T) {
+	// the C buffers of the branches are released along with the tree.
+	for i := 0; i != 10; i++ {
+		tree := croot.NewTree("tree", "tree", 32)
+		var evt struct {
+			Ok  bool
+			Vec []float64
+			Str string
+		}
+		if _, err := tree.Branch2("ok", &evt.Ok, "ok/O", 32000); err != nil {
+			t.Fatalf(err.Error())
+		}
+		if _, err := tree.Branch("vec", &evt.Vec, 32000, 0); err != nil {
+			t.Fatalf(err.Error())
+		}
+		if _, err := tree.Branch("str", &evt.Str, 32000, 0); err != nil {
+			t.Fatalf(err.Error())
+		}
+		evt.Ok = true
+		evt.Vec = []float64{1, 2, 3}
+		evt.Str = "evt"
+		if _, err := tree.Fill(); err != nil {
+			t.Fatalf(err.Error())
+		}
+		if n := tree.GetEntries(); n != 1 {
+			t.Fatalf("expected 1 entry, got %d", n)
+		}
+		tree.Delete()
+	}
+}
+
+func TestGenCxxDict(t *testing.T) {
+	dir, err := ioutil.TempDir("", "croot-cxxdict-")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	err = croot.GenCxxDict(dir, "event",
+		reflect.TypeOf(Event{}),
+		reflect.TypeOf(DataSlice{}),
+		reflect.TypeOf(DataArray{}),
+		reflect.TypeOf(&DataString{}),
+	)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for fname, want := range map[string][]string{
+		"event.h": {
+			"#ifndef CROOT_GEN_EVENT_H",
+			"struct float64;",
+			"struct string {\n    int32_t Len;\n    char *Data; //[Len]\n  };",
+			"struct slice<float64> {\n    int32_t Len;\n    int32_t Cap;\n    double *Data; //[Len]\n  };",
+			"struct Det {\n  double E;\n  double T;\n};",
+			"struct Event {\n  int64_t I;\n  Det A;\n  Det B;\n};",
+			"  golang::slice<float64> Slice;\n",
+			"  double Array[2];\n",
+			"  golang::string String;\n",
+		},
+		"eventLinkDef.h": {
+			"#pragma link C++ class golang::string+;",
+			"#pragma link C++ class golang::slice<float64>+;",
+			"#pragma link C++ class Det+;\n#pragma link C++ class Event+;",
+			"#pragma link C++ class DataString+;",
+		},
+		"event_selection.xml": {
+			`<class name="golang::slice<float64>" />`,
+			`<class name="DataArray" />`,
+		},
+	} {
+		buf, err := ioutil.ReadFile(filepath.Join(dir, fname))
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		for _, w := range want {
+			if !strings.Contains(string(buf), w) {
+				t.Errorf("%s: expected %q in:\n%s", fname, w, buf)
+			}
+		}
+	}
+
+	type Flags struct {
+		Ok bool
+	}
+	err = croot.GenCxxDict(dir, "flags", reflect.TypeOf(Flags{}))
+	if err == nil {
+		t.Errorf("expected an error declaring a bool")
+	}
+}
+
 // EOF
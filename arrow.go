@@ -0,0 +1,668 @@
+package croot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// arrow_batch_size is the maximum number of entries of an Arrow record batch
+const arrow_batch_size = 64 * 1024
+
+// ExportArrow writes the entries of t to w as an Apache Arrow IPC file
+// (also known as Feather v2) and returns the number of exported entries.
+// Leaves are mapped to Arrow primitive types (Bool, Int, FloatingPoint,
+// Utf8 for C-strings), fixed-size arrays to FixedSizeList columns and
+// variable-size arrays and std::vectors to List columns.
+// Branches with several leaves and split objects are mapped to Struct
+// columns.
+// Entries are written by record batches of at most 65536 entries.
+// opts.Explode is ignored.
+func ExportArrow(w io.Writer, t Tree, opts ExportOptions) (int64, error) {
+	aw := &arrow_writer{w: w}
+	err := aw.write([]byte("ARROW1\x00\x00"))
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := export_entries(t, opts, func(r *Reader) error {
+		aw.fields = arrow_fields(r.Columns())
+		meta := fb_encode(arrow_message(1, aw.schema(), 0))
+		_, err := aw.message(meta, nil)
+		return err
+	}, func(vals []interface{}) error {
+		for _, f := range aw.fields {
+			s, ok := f.arr.(*arrow_struct)
+			if !ok {
+				f.arr.append(reflect.ValueOf(vals[f.index[0]]))
+				continue
+			}
+			for i, idx := range f.index {
+				s.children[i].append(reflect.ValueOf(vals[idx]))
+			}
+			s.n++
+		}
+		aw.nrows++
+		if aw.nrows >= arrow_batch_size {
+			return aw.flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return n, err
+	}
+
+	err = aw.flush()
+	if err != nil {
+		return n, err
+	}
+	// end-of-stream marker
+	err = aw.write([]byte{0xff, 0xff, 0xff, 0xff, 0, 0, 0, 0})
+	if err != nil {
+		return n, err
+	}
+
+	footer := &fb_table{}
+	footer.add_int16(0, arrow_metadata_v5)
+	footer.add_offset(1, aw.schema())
+	footer.add_offset(2, &fb_struct_vector{size: 24})
+	footer.add_offset(3, &fb_struct_vector{size: 24, data: aw.blocks.Bytes()})
+	fbuf := fb_encode(footer)
+	err = aw.write(fbuf)
+	if err != nil {
+		return n, err
+	}
+	var tail [4]byte
+	binary.LittleEndian.PutUint32(tail[:], uint32(len(fbuf)))
+	err = aw.write(tail[:])
+	if err != nil {
+		return n, err
+	}
+	return n, aw.write([]byte("ARROW1"))
+}
+
+// arrow_metadata_v5 is the version of the Arrow metadata written out
+const arrow_metadata_v5 = 4
+
+// arrow_writer writes the messages of an Arrow IPC file
+type arrow_writer struct {
+	w      io.Writer
+	pos    int64
+	fields []*arrow_field
+	nrows  int64        // number of rows of the current record batch
+	blocks bytes.Buffer // Blocks of the record batches, for the footer
+}
+
+func (aw *arrow_writer) write(p []byte) error {
+	n, err := aw.w.Write(p)
+	aw.pos += int64(n)
+	return err
+}
+
+func (aw *arrow_writer) schema() *fb_table {
+	fields := make([]*fb_table, len(aw.fields))
+	for i, f := range aw.fields {
+		fields[i] = f.arr.field(f.name)
+	}
+	schema := &fb_table{}
+	schema.add_int16(0, 0) // little-endian
+	schema.add_offset(1, fb_table_vector(fields))
+	return schema
+}
+
+// message writes an encapsulated message and returns the size of its
+// metadata, prefix and padding included.
+func (aw *arrow_writer) message(meta, body []byte) (int, error) {
+	size := align8(len(meta) + 8)
+	var prefix [8]byte
+	binary.LittleEndian.PutUint32(prefix[0:], 0xffffffff)
+	binary.LittleEndian.PutUint32(prefix[4:], uint32(size-8))
+	err := aw.write(prefix[:])
+	if err != nil {
+		return size, err
+	}
+	err = aw.write(append(meta, make([]byte, size-8-len(meta))...))
+	if err != nil {
+		return size, err
+	}
+	return size, aw.write(body)
+}
+
+// flush writes the pending rows as a record batch.
+func (aw *arrow_writer) flush() error {
+	if aw.nrows == 0 {
+		return nil
+	}
+	var (
+		nodes   bytes.Buffer
+		buffers bytes.Buffer
+		body    []byte
+	)
+	for _, f := range aw.fields {
+		f.arr.flatten(func(length int) {
+			binary.Write(&nodes, binary.LittleEndian, [2]int64{int64(length), 0})
+		}, func(buf []byte) {
+			binary.Write(&buffers, binary.LittleEndian, [2]int64{int64(len(body)), int64(len(buf))})
+			body = append(body, buf...)
+			body = append(body, make([]byte, align8(len(buf))-len(buf))...)
+		})
+		f.arr.reset()
+	}
+
+	batch := &fb_table{}
+	batch.add_int64(0, aw.nrows)
+	batch.add_offset(1, &fb_struct_vector{size: 16, data: nodes.Bytes()})
+	batch.add_offset(2, &fb_struct_vector{size: 16, data: buffers.Bytes()})
+
+	offset := aw.pos
+	meta := fb_encode(arrow_message(3, batch, int64(len(body))))
+	size, err := aw.message(meta, body)
+	if err != nil {
+		return err
+	}
+	binary.Write(&aw.blocks, binary.LittleEndian, struct {
+		Offset   int64
+		MetaLen  int32
+		_        int32
+		BodySize int64
+	}{offset, int32(size), 0, int64(len(body))})
+	aw.nrows = 0
+	return nil
+}
+
+// arrow_message returns a Message of the given header type.
+func arrow_message(htype uint8, header *fb_table, bodylen int64) *fb_table {
+	msg := &fb_table{}
+	msg.add_int16(0, arrow_metadata_v5)
+	msg.add_uint8(1, htype)
+	msg.add_offset(2, header)
+	msg.add_int64(3, bodylen)
+	return msg
+}
+
+func align8(n int) int {
+	return (n + 7) &^ 7
+}
+
+// arrow_field is a top-level column of an Arrow file
+type arrow_field struct {
+	name  string
+	arr   arrow_array
+	index []int // indices of the Reader columns (one per child of structs)
+}
+
+// arrow_fields maps the columns of a Reader to Arrow columns: columns of
+// the same multi-leaves branch or split object are grouped into a Struct.
+func arrow_fields(cols []Column) []*arrow_field {
+	var fields []*arrow_field
+	groups := make(map[string]*arrow_field)
+	for i, col := range cols {
+		group := ""
+		br := col.Leaf.GetBranch()
+		if mother := br.GetMother(); mother != nil && mother.GetName() != br.GetName() {
+			group = strings.TrimSuffix(mother.GetName(), ".")
+		} else if col.Name != col.Branch {
+			group = col.Branch
+		}
+		if group == "" {
+			fields = append(fields, &arrow_field{
+				name:  col.Name,
+				arr:   new_arrow_array(col.Type),
+				index: []int{i},
+			})
+			continue
+		}
+		f, ok := groups[group]
+		if !ok {
+			f = &arrow_field{name: group, arr: &arrow_struct{}}
+			groups[group] = f
+			fields = append(fields, f)
+		}
+		s := f.arr.(*arrow_struct)
+		s.names = append(s.names, strings.TrimPrefix(col.Name, group+"."))
+		s.children = append(s.children, new_arrow_array(col.Type))
+		f.index = append(f.index, i)
+	}
+	return fields
+}
+
+// arrow_array accumulates the values of an Arrow column
+type arrow_array interface {
+	// field returns the description of the column.
+	field(name string) *fb_table
+	append(v reflect.Value)
+	// flatten hands the field nodes and buffers of the column, in
+	// depth-first order.
+	flatten(node func(length int), buffer func(buf []byte))
+	reset()
+}
+
+func new_arrow_array(typ reflect.Type) arrow_array {
+	switch typ.Kind() {
+	case reflect.Bool:
+		return &arrow_bool{}
+	case reflect.String:
+		return &arrow_string{offsets: []int32{0}}
+	case reflect.Array:
+		return &arrow_fixed_list{size: typ.Len(), child: new_arrow_array(typ.Elem())}
+	case reflect.Slice:
+		return &arrow_list{offsets: []int32{0}, child: new_arrow_array(typ.Elem())}
+	}
+	return &arrow_primitive{typ: typ}
+}
+
+// arrow_field_table returns a (non-nullable) Field table.
+func arrow_field_table(name string, ttype uint8, typ *fb_table, children ...*fb_table) *fb_table {
+	f := &fb_table{}
+	f.add_offset(0, fb_string(name))
+	f.add_bool(1, false)
+	f.add_uint8(2, ttype)
+	f.add_offset(3, typ)
+	f.add_offset(5, fb_table_vector(children))
+	return f
+}
+
+// arrow_primitive is a column of integers or floats
+type arrow_primitive struct {
+	typ  reflect.Type
+	n    int
+	data bytes.Buffer
+}
+
+func (a *arrow_primitive) field(name string) *fb_table {
+	typ := &fb_table{}
+	switch a.typ.Kind() {
+	case reflect.Float32:
+		typ.add_int16(0, 1)
+		return arrow_field_table(name, 3, typ)
+	case reflect.Float64:
+		typ.add_int16(0, 2)
+		return arrow_field_table(name, 3, typ)
+	}
+	typ.add_int32(0, int32(8*a.typ.Size()))
+	switch a.typ.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		typ.add_bool(1, true)
+	default:
+		typ.add_bool(1, false)
+	}
+	return arrow_field_table(name, 2, typ)
+}
+
+func (a *arrow_primitive) append(v reflect.Value) {
+	binary.Write(&a.data, binary.LittleEndian, v.Interface())
+	a.n++
+}
+
+func (a *arrow_primitive) flatten(node func(int), buffer func([]byte)) {
+	node(a.n)
+	buffer(nil) // validity bitmap: no null values
+	buffer(a.data.Bytes())
+}
+
+func (a *arrow_primitive) reset() {
+	a.n = 0
+	a.data.Reset()
+}
+
+// arrow_bool is a column of booleans
+type arrow_bool struct {
+	n    int
+	bits []byte
+}
+
+func (a *arrow_bool) field(name string) *fb_table {
+	return arrow_field_table(name, 6, &fb_table{})
+}
+
+func (a *arrow_bool) append(v reflect.Value) {
+	if a.n%8 == 0 {
+		a.bits = append(a.bits, 0)
+	}
+	if v.Bool() {
+		a.bits[a.n/8] |= 1 << uint(a.n%8)
+	}
+	a.n++
+}
+
+func (a *arrow_bool) flatten(node func(int), buffer func([]byte)) {
+	node(a.n)
+	buffer(nil)
+	buffer(a.bits)
+}
+
+func (a *arrow_bool) reset() {
+	a.n = 0
+	a.bits = a.bits[:0]
+}
+
+// arrow_string is a column of UTF-8 strings
+type arrow_string struct {
+	offsets []int32
+	data    []byte
+}
+
+func (a *arrow_string) field(name string) *fb_table {
+	return arrow_field_table(name, 5, &fb_table{})
+}
+
+func (a *arrow_string) append(v reflect.Value) {
+	a.data = append(a.data, v.String()...)
+	a.offsets = append(a.offsets, int32(len(a.data)))
+}
+
+func (a *arrow_string) flatten(node func(int), buffer func([]byte)) {
+	node(len(a.offsets) - 1)
+	buffer(nil)
+	buffer(int32s_bytes(a.offsets))
+	buffer(a.data)
+}
+
+func (a *arrow_string) reset() {
+	a.offsets = a.offsets[:1]
+	a.data = a.data[:0]
+}
+
+// arrow_list is a column of variable-size lists
+type arrow_list struct {
+	offsets []int32
+	child   arrow_array
+}
+
+func (a *arrow_list) field(name string) *fb_table {
+	return arrow_field_table(name, 12, &fb_table{}, a.child.field("item"))
+}
+
+func (a *arrow_list) append(v reflect.Value) {
+	n := v.Len()
+	for i := 0; i < n; i++ {
+		a.child.append(v.Index(i))
+	}
+	a.offsets = append(a.offsets, a.offsets[len(a.offsets)-1]+int32(n))
+}
+
+func (a *arrow_list) flatten(node func(int), buffer func([]byte)) {
+	node(len(a.offsets) - 1)
+	buffer(nil)
+	buffer(int32s_bytes(a.offsets))
+	a.child.flatten(node, buffer)
+}
+
+func (a *arrow_list) reset() {
+	a.offsets = a.offsets[:1]
+	a.child.reset()
+}
+
+// arrow_fixed_list is a column of fixed-size lists
+type arrow_fixed_list struct {
+	size  int
+	n     int
+	child arrow_array
+}
+
+func (a *arrow_fixed_list) field(name string) *fb_table {
+	typ := &fb_table{}
+	typ.add_int32(0, int32(a.size))
+	return arrow_field_table(name, 16, typ, a.child.field("item"))
+}
+
+func (a *arrow_fixed_list) append(v reflect.Value) {
+	for i := 0; i < a.size; i++ {
+		a.child.append(v.Index(i))
+	}
+	a.n++
+}
+
+func (a *arrow_fixed_list) flatten(node func(int), buffer func([]byte)) {
+	node(a.n)
+	buffer(nil)
+	a.child.flatten(node, buffer)
+}
+
+func (a *arrow_fixed_list) reset() {
+	a.n = 0
+	a.child.reset()
+}
+
+// arrow_struct is a column of structs, whose children are filled directly
+type arrow_struct struct {
+	n        int
+	names    []string
+	children []arrow_array
+}
+
+func (a *arrow_struct) field(name string) *fb_table {
+	children := make([]*fb_table, len(a.children))
+	for i, c := range a.children {
+		children[i] = c.field(a.names[i])
+	}
+	return arrow_field_table(name, 13, &fb_table{}, children...)
+}
+
+func (a *arrow_struct) append(v reflect.Value) {
+	panic("croot: arrow structs are filled through their children")
+}
+
+func (a *arrow_struct) flatten(node func(int), buffer func([]byte)) {
+	node(a.n)
+	buffer(nil)
+	for _, c := range a.children {
+		c.flatten(node, buffer)
+	}
+}
+
+func (a *arrow_struct) reset() {
+	a.n = 0
+	for _, c := range a.children {
+		c.reset()
+	}
+}
+
+func int32s_bytes(vs []int32) []byte {
+	buf := make([]byte, 4*len(vs))
+	for i, v := range vs {
+		binary.LittleEndian.PutUint32(buf[4*i:], uint32(v))
+	}
+	return buf
+}
+
+// fb_object is a FlatBuffers object (table, vector or string) referenced
+// by an offset.
+type fb_object interface {
+	// encode appends the object to the builder and returns its position.
+	encode(b *fb_builder) int
+}
+
+// fb_builder lays out FlatBuffers front to back: referenced objects are
+// always written after the offsets pointing to them.
+type fb_builder struct {
+	buf []byte
+}
+
+func (b *fb_builder) align(n int) {
+	for len(b.buf)%n != 0 {
+		b.buf = append(b.buf, 0)
+	}
+}
+
+// fb_encode returns the FlatBuffers encoding of the root table.
+func fb_encode(root *fb_table) []byte {
+	b := &fb_builder{buf: make([]byte, 4, 256)}
+	pos := root.encode(b)
+	binary.LittleEndian.PutUint32(b.buf[0:], uint32(pos))
+	return b.buf
+}
+
+// fb_table is a FlatBuffers table, whose fields are indexed by id
+type fb_table struct {
+	fields map[int]fb_field
+}
+
+type fb_field struct {
+	size  int    // size of scalars, 0 for offsets
+	value uint64 // value of scalars
+	obj   fb_object
+}
+
+func (t *fb_table) set(id int, f fb_field) {
+	if t.fields == nil {
+		t.fields = make(map[int]fb_field)
+	}
+	t.fields[id] = f
+}
+
+func (t *fb_table) add_bool(id int, v bool) {
+	f := fb_field{size: 1}
+	if v {
+		f.value = 1
+	}
+	t.set(id, f)
+}
+
+func (t *fb_table) add_uint8(id int, v uint8) {
+	t.set(id, fb_field{size: 1, value: uint64(v)})
+}
+
+func (t *fb_table) add_int16(id int, v int16) {
+	t.set(id, fb_field{size: 2, value: uint64(uint16(v))})
+}
+
+func (t *fb_table) add_int32(id int, v int32) {
+	t.set(id, fb_field{size: 4, value: uint64(uint32(v))})
+}
+
+func (t *fb_table) add_int64(id int, v int64) {
+	t.set(id, fb_field{size: 8, value: uint64(v)})
+}
+
+func (t *fb_table) add_offset(id int, obj fb_object) {
+	t.set(id, fb_field{obj: obj})
+}
+
+func (t *fb_table) encode(b *fb_builder) int {
+	ids := make([]int, 0, len(t.fields))
+	nids := 0
+	for id := range t.fields {
+		ids = append(ids, id)
+		if id+1 > nids {
+			nids = id + 1
+		}
+	}
+	// lay out the largest fields first to limit padding.
+	sort.Slice(ids, func(i, j int) bool {
+		si, sj := fb_field_size(t.fields[ids[i]]), fb_field_size(t.fields[ids[j]])
+		if si != sj {
+			return si > sj
+		}
+		return ids[i] < ids[j]
+	})
+	offsets := make(map[int]int, len(ids))
+	size := 4 // soffset to the vtable
+	for _, id := range ids {
+		n := fb_field_size(t.fields[id])
+		size = (size + n - 1) / n * n
+		offsets[id] = size
+		size += n
+	}
+
+	// the vtable precedes the table.
+	b.align(2)
+	vtable := len(b.buf)
+	for _, v := range []int{4 + 2*nids, size} {
+		b.buf = append(b.buf, byte(v), byte(v>>8))
+	}
+	for id := 0; id < nids; id++ {
+		v := offsets[id]
+		b.buf = append(b.buf, byte(v), byte(v>>8))
+	}
+
+	b.align(8)
+	pos := len(b.buf)
+	b.buf = append(b.buf, make([]byte, size)...)
+	binary.LittleEndian.PutUint32(b.buf[pos:], uint32(pos-vtable))
+	for _, id := range ids {
+		f := t.fields[id]
+		p := b.buf[pos+offsets[id]:]
+		switch f.size {
+		case 1:
+			p[0] = byte(f.value)
+		case 2:
+			binary.LittleEndian.PutUint16(p, uint16(f.value))
+		case 4:
+			binary.LittleEndian.PutUint32(p, uint32(f.value))
+		case 8:
+			binary.LittleEndian.PutUint64(p, f.value)
+		}
+	}
+	for _, id := range ids {
+		f := t.fields[id]
+		if f.obj == nil {
+			continue
+		}
+		at := pos + offsets[id]
+		child := f.obj.encode(b)
+		binary.LittleEndian.PutUint32(b.buf[at:], uint32(child-at))
+	}
+	return pos
+}
+
+func fb_field_size(f fb_field) int {
+	if f.obj != nil {
+		return 4
+	}
+	return f.size
+}
+
+// fb_string is a FlatBuffers string
+type fb_string string
+
+func (s fb_string) encode(b *fb_builder) int {
+	b.align(4)
+	pos := len(b.buf)
+	var n [4]byte
+	binary.LittleEndian.PutUint32(n[:], uint32(len(s)))
+	b.buf = append(b.buf, n[:]...)
+	b.buf = append(b.buf, s...)
+	b.buf = append(b.buf, 0)
+	return pos
+}
+
+// fb_table_vector is a FlatBuffers vector of tables
+type fb_table_vector []*fb_table
+
+func (v fb_table_vector) encode(b *fb_builder) int {
+	b.align(4)
+	pos := len(b.buf)
+	b.buf = append(b.buf, make([]byte, 4+4*len(v))...)
+	binary.LittleEndian.PutUint32(b.buf[pos:], uint32(len(v)))
+	for i, t := range v {
+		at := pos + 4 + 4*i
+		child := t.encode(b)
+		binary.LittleEndian.PutUint32(b.buf[at:], uint32(child-at))
+	}
+	return pos
+}
+
+// fb_struct_vector is a FlatBuffers vector of (8-bytes aligned) structs of
+// the given size
+type fb_struct_vector struct {
+	size int
+	data []byte
+}
+
+func (v *fb_struct_vector) encode(b *fb_builder) int {
+	// the elements following the length have to be 8-bytes aligned.
+	b.align(8)
+	b.buf = append(b.buf, 0, 0, 0, 0)
+	pos := len(b.buf)
+	var n [4]byte
+	binary.LittleEndian.PutUint32(n[:], uint32(len(v.data)/v.size))
+	b.buf = append(b.buf, n[:]...)
+	b.buf = append(b.buf, v.data...)
+	return pos
+}
+
+// EOF
@@ -33,10 +33,14 @@
 #include "croot/croot_cintex.h"
 #include "croot/croot_cint.h"
 #include "croot/croot_class.h"
+#include "croot/croot_directory.h"
+#include "croot/croot_entrylist.h"
 #include "croot/croot_file.h"
+#include "croot/croot_filemerger.h"
 #include "croot/croot_hist.h"
 #include "croot/croot_leaf.h"
 #include "croot/croot_math.h"
+#include "croot/croot_minimizer.h"
 #include "croot/croot_object.h"
 #include "croot/croot_objarray.h"
 #include "croot/croot_random.h"
@@ -11,6 +11,16 @@ CROOT_API
 CRoot_Class
 CRoot_Class_GetClass(const char *);
 
+/** New returns a new (default constructed) instance of the class. */
+CROOT_API
+void*
+CRoot_Class_New(CRoot_Class self);
+
+/** Destructor destructs and deallocates an instance created by New. */
+CROOT_API
+void
+CRoot_Class_Destructor(CRoot_Class self, void *obj);
+
 #ifdef __cplusplus
 }
 #endif
@@ -0,0 +1,41 @@
+#ifndef CROOT_CROOT_DIRECTORY_H
+#define CROOT_CROOT_DIRECTORY_H 1
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+/* TDirectory */
+
+CROOT_API
+CRoot_Bool
+CRoot_Directory_cd(CRoot_Directory self, const char *path);
+
+CROOT_API
+CRoot_Object
+CRoot_Directory_Get(CRoot_Directory self, const char *namecycle);
+
+CROOT_API
+int32_t
+CRoot_Directory_GetNkeys(CRoot_Directory self);
+
+CROOT_API
+CRoot_Directory
+CRoot_Directory_mkdir(CRoot_Directory self, const char *name, const char *title);
+
+/** GetKey returns the name, title, class name, cycle, size on file and
+ *  uncompressed size of the i-th key of the directory.
+ *  The strings are owned by the directory.
+ */
+CROOT_API
+CRoot_Bool
+CRoot_Directory_GetKey(CRoot_Directory self, int32_t i,
+                       const char **name, const char **title,
+                       const char **classname, int16_t *cycle,
+                       int32_t *nbytes, int32_t *objlen);
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif /* !CROOT_CROOT_DIRECTORY_H */
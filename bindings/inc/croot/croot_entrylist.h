@@ -0,0 +1,51 @@
+#ifndef CROOT_CROOT_ENTRYLIST_H
+#define CROOT_CROOT_ENTRYLIST_H 1
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+/* TEntryList */
+
+CROOT_API
+CRoot_EntryList
+CRoot_EntryList_new(const char *name, const char *title, CRoot_Tree tree);
+
+CROOT_API
+CRoot_EntryList
+CRoot_EntryList_newFromEventList(CRoot_EventList elist);
+
+CROOT_API
+CRoot_EntryList
+CRoot_EntryList_newFromSelection(CRoot_Tree tree,
+                                 const char *name, const char *selection);
+
+CROOT_API
+void
+CRoot_EntryList_delete(CRoot_EntryList self);
+
+CROOT_API
+CRoot_Bool
+CRoot_EntryList_Enter(CRoot_EntryList self, int64_t entry, CRoot_Tree tree);
+
+CROOT_API
+int32_t
+CRoot_EntryList_Contains(CRoot_EntryList self, int64_t entry, CRoot_Tree tree);
+
+CROOT_API
+int64_t
+CRoot_EntryList_GetEntry(CRoot_EntryList self, int32_t index);
+
+CROOT_API
+int64_t
+CRoot_EntryList_GetN(CRoot_EntryList self);
+
+CROOT_API
+void
+CRoot_EntryList_SetTree(CRoot_EntryList self, CRoot_Tree tree);
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif /* !CROOT_CROOT_ENTRYLIST_H */
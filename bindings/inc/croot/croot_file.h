@@ -43,6 +43,22 @@ CROOT_API
 CRoot_Bool
 CRoot_File_IsOpen(CRoot_File self);
 
+CROOT_API
+int32_t
+CRoot_File_GetCompressionSettings(CRoot_File self);
+
+CROOT_API
+void
+CRoot_File_SetCompressionSettings(CRoot_File self, int32_t settings);
+
+CROOT_API
+int64_t
+CRoot_File_GetBytesRead(CRoot_File self);
+
+CROOT_API
+int32_t
+CRoot_File_GetReadCalls(CRoot_File self);
+
 CROOT_API
 CRoot_Bool
 CRoot_File_ReadBuffer(CRoot_File self,
@@ -0,0 +1,51 @@
+#ifndef CROOT_CROOT_FILEMERGER_H
+#define CROOT_CROOT_FILEMERGER_H 1
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+/* TFileMerger */
+
+CROOT_API
+CRoot_FileMerger
+CRoot_FileMerger_new(CRoot_Bool isLocal);
+
+CROOT_API
+void
+CRoot_FileMerger_delete(CRoot_FileMerger self);
+
+CROOT_API
+CRoot_Bool
+CRoot_FileMerger_OutputFile(CRoot_FileMerger self, const char *url,
+                            CRoot_Bool force, int32_t compress);
+
+CROOT_API
+CRoot_Bool
+CRoot_FileMerger_AddFile(CRoot_FileMerger self, const char *url);
+
+/** AddObjectNames restricts the merge to the blank-separated list of
+ *  top-level keys names. It returns false if the version of ROOT does
+ *  not support partial merges.
+ */
+CROOT_API
+CRoot_Bool
+CRoot_FileMerger_AddObjectNames(CRoot_FileMerger self, const char *names);
+
+CROOT_API
+void
+CRoot_FileMerger_SetFastMethod(CRoot_FileMerger self, CRoot_Bool fast);
+
+CROOT_API
+void
+CRoot_FileMerger_SetPrintLevel(CRoot_FileMerger self, int32_t level);
+
+CROOT_API
+CRoot_Bool
+CRoot_FileMerger_Merge(CRoot_FileMerger self);
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif /* !CROOT_CROOT_FILEMERGER_H */
@@ -11,6 +11,10 @@ CROOT_API
 CRoot_Branch
 CRoot_Leaf_GetBranch(CRoot_Leaf self);
 
+CROOT_API
+int
+CRoot_Leaf_GetLen(CRoot_Leaf self);
+
 CROOT_API
 int
 CRoot_Leaf_GetLenStatic(CRoot_Leaf self);
@@ -31,6 +35,22 @@ CROOT_API
 void
 CRoot_Leaf_SetAddress(CRoot_Leaf self, void* addr);
 
+/** GetVectorData retrieves the content of the std::vector of builtins held
+ *  by the branch of the leaf: data points to its len elements.
+ *  It returns false if the branch does not hold such a std::vector.
+ */
+CROOT_API
+CRoot_Bool
+CRoot_Leaf_GetVectorData(CRoot_Leaf self, void **data, int64_t *len);
+
+/** GetStringData retrieves the content of the std::string held by the
+ *  branch of the leaf.
+ *  It returns false if the branch does not hold a std::string.
+ */
+CROOT_API
+CRoot_Bool
+CRoot_Leaf_GetStringData(CRoot_Leaf self, const char **data, int64_t *len);
+
 /* TLeafI */
 
 CROOT_API
@@ -112,6 +112,68 @@ CROOT_API
 double
 CRoot_Math_Log10(double);
 
+/* TMath statistics */
+
+CROOT_API
+double
+CRoot_Math_Gaus(double x, double mean, double sigma, CRoot_Bool norm);
+
+CROOT_API
+double
+CRoot_Math_Landau(double x, double mpv, double sigma, CRoot_Bool norm);
+
+CROOT_API
+double
+CRoot_Math_BreitWigner(double x, double mean, double gamma);
+
+CROOT_API
+double
+CRoot_Math_Poisson(double x, double par);
+
+CROOT_API
+double
+CRoot_Math_PoissonI(double x, double par);
+
+CROOT_API
+double
+CRoot_Math_Prob(double chi2, int32_t ndf);
+
+CROOT_API
+double
+CRoot_Math_Erf(double);
+
+CROOT_API
+double
+CRoot_Math_Erfc(double);
+
+CROOT_API
+double
+CRoot_Math_ErfInverse(double);
+
+CROOT_API
+double
+CRoot_Math_ErfcInverse(double);
+
+CROOT_API
+double
+CRoot_Math_Freq(double);
+
+CROOT_API
+double
+CRoot_Math_ChisquareQuantile(double p, double ndf);
+
+CROOT_API
+double
+CRoot_Math_Student(double t, double ndf);
+
+CROOT_API
+double
+CRoot_Math_StudentI(double t, double ndf);
+
+CROOT_API
+double
+CRoot_Math_StudentQuantile(double p, double ndf, CRoot_Bool lower_tail);
+
 #ifdef __cplusplus
 }
 #endif
@@ -0,0 +1,151 @@
+#ifndef CROOT_CROOT_MINIMIZER_H
+#define CROOT_CROOT_MINIMIZER_H 1
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+/* ROOT::Math::Minimizer */
+
+/** typedef for the objective function: ctx is an opaque handle given back
+ *  to the function, x holds the npar current values of the parameters.
+ */
+typedef double (*CRoot_Minimizer_Fcn)(int64_t ctx, int32_t npar, double *x);
+
+CROOT_API
+CRoot_Minimizer
+CRoot_Minimizer_new(const char *type, const char *algo);
+
+CROOT_API
+void
+CRoot_Minimizer_delete(CRoot_Minimizer self);
+
+CROOT_API
+void
+CRoot_Minimizer_SetFunction(CRoot_Minimizer self,
+                            CRoot_Minimizer_Fcn fcn, int64_t ctx,
+                            int32_t npar);
+
+CROOT_API
+CRoot_Bool
+CRoot_Minimizer_SetVariable(CRoot_Minimizer self,
+                            int32_t ivar, const char *name,
+                            double val, double step);
+
+CROOT_API
+CRoot_Bool
+CRoot_Minimizer_SetLimitedVariable(CRoot_Minimizer self,
+                                   int32_t ivar, const char *name,
+                                   double val, double step,
+                                   double lower, double upper);
+
+CROOT_API
+CRoot_Bool
+CRoot_Minimizer_SetLowerLimitedVariable(CRoot_Minimizer self,
+                                        int32_t ivar, const char *name,
+                                        double val, double step,
+                                        double lower);
+
+CROOT_API
+CRoot_Bool
+CRoot_Minimizer_SetUpperLimitedVariable(CRoot_Minimizer self,
+                                        int32_t ivar, const char *name,
+                                        double val, double step,
+                                        double upper);
+
+CROOT_API
+CRoot_Bool
+CRoot_Minimizer_SetFixedVariable(CRoot_Minimizer self,
+                                 int32_t ivar, const char *name,
+                                 double val);
+
+CROOT_API
+CRoot_Bool
+CRoot_Minimizer_FixVariable(CRoot_Minimizer self, int32_t ivar);
+
+CROOT_API
+CRoot_Bool
+CRoot_Minimizer_ReleaseVariable(CRoot_Minimizer self, int32_t ivar);
+
+CROOT_API
+CRoot_Bool
+CRoot_Minimizer_IsFixedVariable(CRoot_Minimizer self, int32_t ivar);
+
+CROOT_API
+void
+CRoot_Minimizer_SetPrintLevel(CRoot_Minimizer self, int32_t level);
+
+CROOT_API
+void
+CRoot_Minimizer_SetStrategy(CRoot_Minimizer self, int32_t strategy);
+
+CROOT_API
+void
+CRoot_Minimizer_SetTolerance(CRoot_Minimizer self, double tol);
+
+CROOT_API
+void
+CRoot_Minimizer_SetErrorDef(CRoot_Minimizer self, double up);
+
+CROOT_API
+void
+CRoot_Minimizer_SetMaxFunctionCalls(CRoot_Minimizer self, uint32_t maxfcn);
+
+CROOT_API
+void
+CRoot_Minimizer_SetMaxIterations(CRoot_Minimizer self, uint32_t maxiter);
+
+CROOT_API
+CRoot_Bool
+CRoot_Minimizer_Minimize(CRoot_Minimizer self);
+
+CROOT_API
+CRoot_Bool
+CRoot_Minimizer_Hesse(CRoot_Minimizer self);
+
+CROOT_API
+CRoot_Bool
+CRoot_Minimizer_GetMinosError(CRoot_Minimizer self, int32_t ivar,
+                              double *errlow, double *errup);
+
+CROOT_API
+int32_t
+CRoot_Minimizer_Status(CRoot_Minimizer self);
+
+CROOT_API
+int32_t
+CRoot_Minimizer_CovMatrixStatus(CRoot_Minimizer self);
+
+CROOT_API
+double
+CRoot_Minimizer_MinValue(CRoot_Minimizer self);
+
+CROOT_API
+double
+CRoot_Minimizer_Edm(CRoot_Minimizer self);
+
+CROOT_API
+uint32_t
+CRoot_Minimizer_NCalls(CRoot_Minimizer self);
+
+CROOT_API
+uint32_t
+CRoot_Minimizer_NDim(CRoot_Minimizer self);
+
+CROOT_API
+void
+CRoot_Minimizer_X(CRoot_Minimizer self, double *x);
+
+CROOT_API
+void
+CRoot_Minimizer_Errors(CRoot_Minimizer self, double *errs);
+
+CROOT_API
+double
+CRoot_Minimizer_CovMatrix(CRoot_Minimizer self, int32_t i, int32_t j);
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif /* !CROOT_CROOT_MINIMIZER_H */
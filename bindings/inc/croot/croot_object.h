@@ -39,6 +39,11 @@ void
 CRoot_Object_Print(CRoot_Object self,
                    CRoot_Option *option);
 
+CROOT_API
+int32_t
+CRoot_Object_Write(CRoot_Object self,
+                   const char *name, int32_t option, int32_t bufsize);
+
 #ifdef __cplusplus
 }
 #endif
@@ -7,15 +7,51 @@ extern "C" {
 
 /* TRandom */
 
+CROOT_API
+CRoot_Random
+CRoot_Random3_new(uint32_t seed);
+
+CROOT_API
+void
+CRoot_Random_delete(CRoot_Random self);
+
+CROOT_API
+void
+CRoot_Random_SetSeed(CRoot_Random self, uint32_t seed);
+
+CROOT_API
+uint32_t
+CRoot_Random_GetSeed(CRoot_Random self);
+
+CROOT_API
+double
+CRoot_Random_BreitWigner(CRoot_Random self, double mean, double gamma);
+
 CROOT_API
 int32_t
 CRoot_Random_Binomial(CRoot_Random self, int32_t ntot, double prob);
 
+CROOT_API
+double
+CRoot_Random_Exp(CRoot_Random self, double tau);
+
 CROOT_API
 double
 CRoot_Random_Gaus(CRoot_Random self,
                   double mean, double sigma);
 
+CROOT_API
+uint32_t
+CRoot_Random_Integer(CRoot_Random self, uint32_t imax);
+
+CROOT_API
+double
+CRoot_Random_Landau(CRoot_Random self, double mean, double sigma);
+
+CROOT_API
+int32_t
+CRoot_Random_Poisson(CRoot_Random self, double mean);
+
 CROOT_API
 void
 CRoot_Random_Rannorf(CRoot_Random self,
@@ -31,6 +67,20 @@ double
 CRoot_Random_Rndm(CRoot_Random self,
                   int32_t i);
 
+CROOT_API
+void
+CRoot_Random_RndmArray(CRoot_Random self,
+                       int32_t n, double *array);
+
+CROOT_API
+void
+CRoot_Random_Sphere(CRoot_Random self,
+                    double *x, double *y, double *z, double r);
+
+CROOT_API
+double
+CRoot_Random_Uniform(CRoot_Random self, double x1, double x2);
+
 #ifdef __cplusplus
 }
 #endif
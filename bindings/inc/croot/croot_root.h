@@ -11,6 +11,13 @@ CRoot_File
 CRoot_ROOT_GetFile(CRoot_ROOT self,
                    const char *name);
 
+/** EnableThreadSafety makes ROOT usable from several threads, each of them
+ *  working on its own files and objects.
+ */
+CROOT_API
+void
+CRoot_ROOT_EnableThreadSafety(void);
+
 #ifdef __cplusplus
 }
 #endif
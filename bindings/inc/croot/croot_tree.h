@@ -11,6 +11,26 @@ CROOT_API
 char*
 CRoot_Branch_GetAddress(CRoot_Branch self);
 
+CROOT_API
+void
+CRoot_Branch_SetAddress(CRoot_Branch self, void *addr);
+
+/** SetVectorData replaces the content of the std::vector of builtins held
+ *  by the branch with the len elements at data.
+ *  It returns false if the branch does not hold such a std::vector.
+ */
+CROOT_API
+CRoot_Bool
+CRoot_Branch_SetVectorData(CRoot_Branch self, const void *data, int64_t len);
+
+/** SetStringData replaces the content of the std::string held by the
+ *  branch with the len bytes at data.
+ *  It returns false if the branch does not hold a std::string.
+ */
+CROOT_API
+CRoot_Bool
+CRoot_Branch_SetStringData(CRoot_Branch self, const char *data, int64_t len);
+
 /* CROOT_API */
 /* char* */
 /* CRoot_Branch_GetObject(CRoot_Branch self); */
@@ -23,10 +43,54 @@ CROOT_API
 CRoot_Leaf
 CRoot_Branch_GetLeaf(CRoot_Branch self, const char* name);
 
+CROOT_API
+CRoot_ObjArray
+CRoot_Branch_GetListOfBranches(CRoot_Branch self);
+
+CROOT_API
+CRoot_Branch
+CRoot_Branch_GetMother(CRoot_Branch self);
+
 CROOT_API
 CRoot_ObjArray
 CRoot_Branch_GetListOfLeaves(CRoot_Branch self);
 
+CROOT_API
+int32_t
+CRoot_Branch_GetBasketSize(CRoot_Branch self);
+
+CROOT_API
+int64_t
+CRoot_Branch_GetEntries(CRoot_Branch self);
+
+CROOT_API
+int64_t
+CRoot_Branch_GetTotBytes(CRoot_Branch self, CRoot_Option *option);
+
+CROOT_API
+int64_t
+CRoot_Branch_GetZipBytes(CRoot_Branch self, CRoot_Option *option);
+
+CROOT_API
+int32_t
+CRoot_Branch_GetReadBasket(CRoot_Branch self);
+
+CROOT_API
+int32_t
+CRoot_Branch_GetWriteBasket(CRoot_Branch self);
+
+CROOT_API
+void
+CRoot_Branch_SetBasketSize(CRoot_Branch self, int32_t bufsize);
+
+CROOT_API
+int32_t
+CRoot_Branch_GetCompressionSettings(CRoot_Branch self);
+
+CROOT_API
+void
+CRoot_Branch_SetCompressionSettings(CRoot_Branch self, int32_t settings);
+
 /* TBranchElement */
 
 CROOT_API
@@ -47,6 +111,11 @@ CROOT_API
 void
 CRoot_Tree_delete(CRoot_Tree self);
 
+CROOT_API
+CRoot_Tree
+CRoot_Tree_AddFriend(CRoot_Tree self,
+                     const char *treename, const char *filename);
+
 CROOT_API
 CRoot_Branch
 CRoot_Tree_Branch(CRoot_Tree self,
@@ -59,6 +128,26 @@ CRoot_Tree_Branch2(CRoot_Tree self,
                    const char *name, void *address, const char *leaflist,
                    int32_t bufsize);
 
+CROOT_API
+CRoot_Tree
+CRoot_Tree_CloneTree(CRoot_Tree self, int64_t nentries);
+
+CROOT_API
+CRoot_Tree
+CRoot_Tree_CopyTree(CRoot_Tree self, const char *selection);
+
+CROOT_API
+int64_t
+CRoot_Tree_Draw(CRoot_Tree self,
+                const char *varexp, const char *selection,
+                CRoot_Option *option,
+                int64_t nentries, int64_t firstentry);
+
+CROOT_API
+int32_t
+CRoot_Tree_BuildIndex(CRoot_Tree self,
+                      const char *majorname, const char *minorname);
+
 CROOT_API
 int
 CRoot_Tree_Fill(CRoot_Tree self);
@@ -72,16 +161,40 @@ CROOT_API
 int64_t
 CRoot_Tree_GetEntries(CRoot_Tree self);
 
+CROOT_API
+int64_t
+CRoot_Tree_GetTotBytes(CRoot_Tree self);
+
+CROOT_API
+int64_t
+CRoot_Tree_GetZipBytes(CRoot_Tree self);
+
 CROOT_API
 int32_t
 CRoot_Tree_GetEntry(CRoot_Tree self,
                     int64_t entry, int32_t getall);
 
+CROOT_API
+CRoot_EntryList
+CRoot_Tree_GetEntryList(CRoot_Tree self);
+
+CROOT_API
+int64_t
+CRoot_Tree_GetEntryNumber(CRoot_Tree self, int64_t entry);
+
 CROOT_API
 CRoot_Leaf
 CRoot_Tree_GetLeaf(CRoot_Tree self,
                    const char *name);
 
+CROOT_API
+int32_t
+CRoot_Tree_GetTreeNumber(CRoot_Tree self);
+
+CROOT_API
+CRoot_Bool
+CRoot_Tree_HasTreeIndex(CRoot_Tree self);
+
 CROOT_API
 CRoot_ObjArray
 CRoot_Tree_GetListOfBranches(CRoot_Tree self);
@@ -90,6 +203,23 @@ CROOT_API
 CRoot_ObjArray
 CRoot_Tree_GetListOfLeaves(CRoot_Tree self);
 
+CROOT_API
+int64_t
+CRoot_Tree_GetEntryNumberWithIndex(CRoot_Tree self,
+                                   int64_t major, int64_t minor);
+
+CROOT_API
+int64_t
+CRoot_Tree_GetAutoFlush(CRoot_Tree self);
+
+CROOT_API
+int64_t
+CRoot_Tree_GetAutoSave(CRoot_Tree self);
+
+CROOT_API
+int64_t
+CRoot_Tree_GetEstimate(CRoot_Tree self);
+
 CROOT_API
 int64_t
 CRoot_Tree_GetSelectedRows(CRoot_Tree self);
@@ -156,6 +286,39 @@ int32_t
 CRoot_Tree_SetBranchAddress(CRoot_Tree self,
                             const char *bname, void *addr, CRoot_Branch *ptr);
 
+CROOT_API
+void
+CRoot_Tree_SetAutoFlush(CRoot_Tree self, int64_t autof);
+
+CROOT_API
+void
+CRoot_Tree_SetAutoSave(CRoot_Tree self, int64_t autos);
+
+CROOT_API
+void
+CRoot_Tree_SetCacheSize(CRoot_Tree self, int64_t cachesize);
+
+CROOT_API
+void
+CRoot_Tree_AddBranchToCache(CRoot_Tree self,
+                            const char *bname, CRoot_Bool subbranches);
+
+CROOT_API
+void
+CRoot_Tree_SetCacheLearnEntries(CRoot_Tree self, int32_t n);
+
+CROOT_API
+void
+CRoot_Tree_StopCacheLearningPhase(CRoot_Tree self);
+
+CROOT_API
+void
+CRoot_Tree_SetEntryList(CRoot_Tree self, CRoot_EntryList elist);
+
+CROOT_API
+void
+CRoot_Tree_SetEstimate(CRoot_Tree self, int64_t nentries);
+
 CROOT_API
 void
 CRoot_Tree_SetBranchStatus(CRoot_Tree self,
@@ -167,6 +330,42 @@ int32_t
 CRoot_Tree_Write(CRoot_Tree self,
                  const char *name, int32_t option, int32_t bufsize);
 
+/* TTreeFormula */
+
+CROOT_API
+CRoot_TreeFormula
+CRoot_TreeFormula_new(const char *name, const char *formula,
+                      CRoot_Tree tree);
+
+CROOT_API
+void
+CRoot_TreeFormula_delete(CRoot_TreeFormula self);
+
+CROOT_API
+double
+CRoot_TreeFormula_EvalInstance(CRoot_TreeFormula self, int32_t i);
+
+CROOT_API
+int32_t
+CRoot_TreeFormula_GetNdata(CRoot_TreeFormula self);
+
+CROOT_API
+int32_t
+CRoot_TreeFormula_GetNdim(CRoot_TreeFormula self);
+
+CROOT_API
+void
+CRoot_TreeFormula_UpdateFormulaLeaves(CRoot_TreeFormula self);
+
+/* number of leaves the formula depends on */
+CROOT_API
+int32_t
+CRoot_TreeFormula_GetNcodes(CRoot_TreeFormula self);
+
+CROOT_API
+CRoot_Leaf
+CRoot_TreeFormula_GetLeaf(CRoot_TreeFormula self, int32_t n);
+
 #ifdef __cplusplus
 }
 #endif
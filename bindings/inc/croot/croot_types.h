@@ -11,11 +11,15 @@ extern "C" {
   typedef void *CRoot_Branch; /* TBranch */
   typedef void *CRoot_BranchElement; /* TBranchElement */
   typedef void *CRoot_Chain; /* TChain */
+  typedef void *CRoot_Directory; /* TDirectory */
+  typedef void *CRoot_EntryList; /* TEntryList */
+  typedef void *CRoot_EventList; /* TEventList */
   typedef void *CRoot_Leaf; /* TLeaf */
   typedef void *CRoot_LeafD; /* TLeafD */
   typedef void *CRoot_LeafF; /* TLeafF */
   typedef void *CRoot_LeafI; /* TLeafI */
   typedef void *CRoot_LeafO; /* TLeafO */
+  typedef void *CRoot_Minimizer; /* ROOT::Math::Minimizer */
   typedef void *CRoot_ObjArray; /* TObjArray */
   typedef void *CRoot_Object; /* TObject */
   typedef void *CRoot_ROOT;  /*TROOT*/
@@ -23,8 +27,10 @@ extern "C" {
   typedef void *CRoot_Cint_TagInfo;
   typedef void *CRoot_Class; /* TClass */
   typedef void *CRoot_File; /* TFile */
+  typedef void *CRoot_FileMerger; /* TFileMerger */
   typedef void *CRoot_H1F; /* TH1F */
   typedef void *CRoot_Tree; /* TTree */
+  typedef void *CRoot_TreeFormula; /* TTreeFormula */
 
   typedef void* CRoot_Reflex_Type;
   typedef void* CRoot_Reflex_Member;
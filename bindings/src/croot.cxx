@@ -4,18 +4,33 @@
 #include "TBranchElement.h"
 #include "TTree.h"
 #include "TChain.h"
+#include "TTreeFormula.h"
+#include "TEntryList.h"
+#include "TFriendElement.h"
 
+#include "TDirectory.h"
 #include "TFile.h"
+#include "TKey.h"
+#include "TList.h"
 
 #include "TObject.h"
 #include "TObjArray.h"
 
 #include "TROOT.h"
+#include "RVersion.h"
+#if ROOT_VERSION_CODE < ROOT_VERSION(6,0,0)
+#include "TThread.h"
+#endif
 #include "TMath.h"
 #include "TRandom.h"
+#include "TRandom3.h"
 
 #include "Api.h"
 
+#include <string.h>
+#include <string>
+#include <vector>
+
 /* TObject */
 const char*
 CRoot_Object_ClassName(CRoot_Object self)
@@ -63,6 +78,13 @@ CRoot_Object_Print(CRoot_Object self,
   return ((TObject*)self)->Print((Option_t*)option);
 }
 
+int32_t
+CRoot_Object_Write(CRoot_Object self,
+                   const char *name, int32_t option, int32_t bufsize)
+{
+  return ((TObject*)self)->Write(name, option, bufsize);
+}
+
 /* TObjArray */
 int64_t
 CRoot_ObjArray_GetSize(CRoot_ObjArray self)
@@ -99,6 +121,16 @@ CRoot_ROOT_GetFile(CRoot_ROOT self,
   return (CRoot_File)f;
 }
 
+void
+CRoot_ROOT_EnableThreadSafety(void)
+{
+#if ROOT_VERSION_CODE >= ROOT_VERSION(6,0,0)
+  ROOT::EnableThreadSafety();
+#else
+  TThread::Initialize();
+#endif
+}
+
 
 /* TTree */
 CRoot_Tree
@@ -116,6 +148,17 @@ CRoot_Tree_delete(CRoot_Tree self)
   self = 0;
 }
 
+CRoot_Tree
+CRoot_Tree_AddFriend(CRoot_Tree self,
+                     const char *treename, const char *filename)
+{
+  TFriendElement *fe = ((TTree*)self)->AddFriend(treename, filename);
+  if (fe == 0) {
+    return 0;
+  }
+  return (CRoot_Tree)(fe->GetTree());
+}
+
 CRoot_Branch
 CRoot_Tree_Branch(CRoot_Tree self,
                   const char *name, const char *classname,
@@ -134,6 +177,35 @@ CRoot_Tree_Branch2(CRoot_Tree self,
                                                leaflist, bufsize));
 }
 
+CRoot_Tree
+CRoot_Tree_CloneTree(CRoot_Tree self, int64_t nentries)
+{
+  return (CRoot_Tree)(((TTree*)self)->CloneTree(nentries));
+}
+
+CRoot_Tree
+CRoot_Tree_CopyTree(CRoot_Tree self, const char *selection)
+{
+  return (CRoot_Tree)(((TTree*)self)->CopyTree(selection));
+}
+
+int64_t
+CRoot_Tree_Draw(CRoot_Tree self,
+                const char *varexp, const char *selection,
+                CRoot_Option *option,
+                int64_t nentries, int64_t firstentry)
+{
+  return ((TTree*)self)->Draw(varexp, selection, (Option_t*)option,
+                              nentries, firstentry);
+}
+
+int32_t
+CRoot_Tree_BuildIndex(CRoot_Tree self,
+                      const char *majorname, const char *minorname)
+{
+  return ((TTree*)self)->BuildIndex(majorname, minorname);
+}
+
 int
 CRoot_Tree_Fill(CRoot_Tree self)
 {
@@ -153,6 +225,18 @@ CRoot_Tree_GetEntries(CRoot_Tree self)
   return ((TTree*)self)->GetEntries();
 }
 
+int64_t
+CRoot_Tree_GetTotBytes(CRoot_Tree self)
+{
+  return ((TTree*)self)->GetTotBytes();
+}
+
+int64_t
+CRoot_Tree_GetZipBytes(CRoot_Tree self)
+{
+  return ((TTree*)self)->GetZipBytes();
+}
+
 int32_t
 CRoot_Tree_GetEntry(CRoot_Tree self,
                     int64_t entry, int32_t getall)
@@ -160,6 +244,18 @@ CRoot_Tree_GetEntry(CRoot_Tree self,
   return ((TTree*)self)->GetEntry(entry, getall);
 }
 
+CRoot_EntryList
+CRoot_Tree_GetEntryList(CRoot_Tree self)
+{
+  return (CRoot_EntryList)(((TTree*)self)->GetEntryList());
+}
+
+int64_t
+CRoot_Tree_GetEntryNumber(CRoot_Tree self, int64_t entry)
+{
+  return ((TTree*)self)->GetEntryNumber(entry);
+}
+
 CRoot_Leaf
 CRoot_Tree_GetLeaf(CRoot_Tree self,
                    const char *name)
@@ -167,6 +263,18 @@ CRoot_Tree_GetLeaf(CRoot_Tree self,
   return (CRoot_Leaf)(((TTree*)self)->GetLeaf(name));
 }
 
+int32_t
+CRoot_Tree_GetTreeNumber(CRoot_Tree self)
+{
+  return ((TTree*)self)->GetTreeNumber();
+}
+
+CRoot_Bool
+CRoot_Tree_HasTreeIndex(CRoot_Tree self)
+{
+  return (CRoot_Bool)(((TTree*)self)->GetTreeIndex() != 0);
+}
+
 CRoot_ObjArray
 CRoot_Tree_GetListOfBranches(CRoot_Tree self)
 {
@@ -179,6 +287,31 @@ CRoot_Tree_GetListOfLeaves(CRoot_Tree self)
   return (CRoot_ObjArray)(((TTree*)self)->GetListOfLeaves());
 }
 
+int64_t
+CRoot_Tree_GetEntryNumberWithIndex(CRoot_Tree self,
+                                   int64_t major, int64_t minor)
+{
+  return ((TTree*)self)->GetEntryNumberWithIndex(major, minor);
+}
+
+int64_t
+CRoot_Tree_GetAutoFlush(CRoot_Tree self)
+{
+  return ((TTree*)self)->GetAutoFlush();
+}
+
+int64_t
+CRoot_Tree_GetAutoSave(CRoot_Tree self)
+{
+  return ((TTree*)self)->GetAutoSave();
+}
+
+int64_t
+CRoot_Tree_GetEstimate(CRoot_Tree self)
+{
+  return ((TTree*)self)->GetEstimate();
+}
+
 int64_t
 CRoot_Tree_GetSelectedRows(CRoot_Tree self)
 {
@@ -284,6 +417,55 @@ CRoot_Tree_SetBranchStatus(CRoot_Tree self,
   return ((TTree*)self)->SetBranchStatus(bname, (Bool_t)status, found);
 }
 
+void
+CRoot_Tree_SetAutoFlush(CRoot_Tree self, int64_t autof)
+{
+  ((TTree*)self)->SetAutoFlush(autof);
+}
+
+void
+CRoot_Tree_SetAutoSave(CRoot_Tree self, int64_t autos)
+{
+  ((TTree*)self)->SetAutoSave(autos);
+}
+
+void
+CRoot_Tree_SetCacheSize(CRoot_Tree self, int64_t cachesize)
+{
+  ((TTree*)self)->SetCacheSize(cachesize);
+}
+
+void
+CRoot_Tree_AddBranchToCache(CRoot_Tree self,
+                            const char *bname, CRoot_Bool subbranches)
+{
+  ((TTree*)self)->AddBranchToCache(bname, (Bool_t)subbranches);
+}
+
+void
+CRoot_Tree_SetCacheLearnEntries(CRoot_Tree self, int32_t n)
+{
+  ((TTree*)self)->SetCacheLearnEntries(n);
+}
+
+void
+CRoot_Tree_StopCacheLearningPhase(CRoot_Tree self)
+{
+  ((TTree*)self)->StopCacheLearningPhase();
+}
+
+void
+CRoot_Tree_SetEntryList(CRoot_Tree self, CRoot_EntryList elist)
+{
+  ((TTree*)self)->SetEntryList((TEntryList*)elist);
+}
+
+void
+CRoot_Tree_SetEstimate(CRoot_Tree self, int64_t nentries)
+{
+  ((TTree*)self)->SetEstimate(nentries);
+}
+
 int32_t
 CRoot_Tree_Write(CRoot_Tree self,
                  const char *name, int32_t option, int32_t bufsize)
@@ -291,6 +473,60 @@ CRoot_Tree_Write(CRoot_Tree self,
   return ((TTree*)self)->Write(name, option, bufsize);
 }
 
+/* TTreeFormula */
+
+CRoot_TreeFormula
+CRoot_TreeFormula_new(const char *name, const char *formula,
+                      CRoot_Tree tree)
+{
+  TTreeFormula *self = new TTreeFormula(name, formula, (TTree*)tree);
+  return (CRoot_TreeFormula)self;
+}
+
+void
+CRoot_TreeFormula_delete(CRoot_TreeFormula self)
+{
+  TTreeFormula *f = (TTreeFormula*)self;
+  delete f;
+  self = 0;
+}
+
+double
+CRoot_TreeFormula_EvalInstance(CRoot_TreeFormula self, int32_t i)
+{
+  return ((TTreeFormula*)self)->EvalInstance(i);
+}
+
+int32_t
+CRoot_TreeFormula_GetNdata(CRoot_TreeFormula self)
+{
+  return ((TTreeFormula*)self)->GetNdata();
+}
+
+int32_t
+CRoot_TreeFormula_GetNdim(CRoot_TreeFormula self)
+{
+  return ((TTreeFormula*)self)->GetNdim();
+}
+
+void
+CRoot_TreeFormula_UpdateFormulaLeaves(CRoot_TreeFormula self)
+{
+  ((TTreeFormula*)self)->UpdateFormulaLeaves();
+}
+
+int32_t
+CRoot_TreeFormula_GetNcodes(CRoot_TreeFormula self)
+{
+  return ((TTreeFormula*)self)->GetNcodes();
+}
+
+CRoot_Leaf
+CRoot_TreeFormula_GetLeaf(CRoot_TreeFormula self, int32_t n)
+{
+  return (CRoot_Leaf)((TTreeFormula*)self)->GetLeaf(n);
+}
+
 /* TChain */
 
 CRoot_Chain
@@ -342,6 +578,74 @@ CRoot_Branch_GetAddress(CRoot_Branch self)
   return ((TBranch*)self)->GetAddress();
 }
 
+void
+CRoot_Branch_SetAddress(CRoot_Branch self, void *addr)
+{
+  ((TBranch*)self)->SetAddress(addr);
+}
+
+template<typename T>
+static
+CRoot_Bool
+croot_vector_assign(void *obj, const void *data, int64_t len)
+{
+  std::vector<T> *vec = (std::vector<T>*)obj;
+  const T *beg = (const T*)data;
+  vec->assign(beg, beg+len);
+  return (CRoot_Bool)1;
+}
+
+CRoot_Bool
+CRoot_Branch_SetVectorData(CRoot_Branch self, const void *data, int64_t len)
+{
+  TBranchElement *br = dynamic_cast<TBranchElement*>((TBranch*)self);
+  if (br == 0 || br->GetObject() == 0) {
+    return (CRoot_Bool)0;
+  }
+  void *obj = br->GetObject();
+  const char *cls = br->GetClassName();
+
+#define CROOT_VECTOR_ASSIGN(T) \
+  if (strcmp(cls, "vector<" #T ">") == 0) { \
+    return croot_vector_assign<T>(obj, data, len); \
+  }
+
+  CROOT_VECTOR_ASSIGN(char)
+  CROOT_VECTOR_ASSIGN(unsigned char)
+  CROOT_VECTOR_ASSIGN(short)
+  CROOT_VECTOR_ASSIGN(unsigned short)
+  CROOT_VECTOR_ASSIGN(int)
+  CROOT_VECTOR_ASSIGN(unsigned int)
+  CROOT_VECTOR_ASSIGN(long)
+  CROOT_VECTOR_ASSIGN(unsigned long)
+  CROOT_VECTOR_ASSIGN(Long64_t)
+  CROOT_VECTOR_ASSIGN(ULong64_t)
+  CROOT_VECTOR_ASSIGN(long long)
+  CROOT_VECTOR_ASSIGN(unsigned long long)
+  CROOT_VECTOR_ASSIGN(float)
+  CROOT_VECTOR_ASSIGN(double)
+  CROOT_VECTOR_ASSIGN(Double32_t)
+
+#undef CROOT_VECTOR_ASSIGN
+
+  return (CRoot_Bool)0;
+}
+
+CRoot_Bool
+CRoot_Branch_SetStringData(CRoot_Branch self, const char *data, int64_t len)
+{
+  TBranchElement *br = dynamic_cast<TBranchElement*>((TBranch*)self);
+  if (br == 0 || br->GetObject() == 0) {
+    return (CRoot_Bool)0;
+  }
+  const char *cls = br->GetClassName();
+  if (strcmp(cls, "string") != 0 && strcmp(cls, "std::string") != 0) {
+    return (CRoot_Bool)0;
+  }
+  ((std::string*)br->GetObject())->assign(data, len);
+  return (CRoot_Bool)1;
+}
+
 // char*
 // CRoot_Branch_GetObject(CRoot_Branch self)
 // {
@@ -354,12 +658,78 @@ CRoot_Branch_GetLeaf(CRoot_Branch self, const char* name)
   return (CRoot_Leaf)((TBranch*)self)->GetLeaf(name);
 }
 
+CRoot_ObjArray
+CRoot_Branch_GetListOfBranches(CRoot_Branch self)
+{
+  return (CRoot_ObjArray)((TBranch*)self)->GetListOfBranches();
+}
+
+CRoot_Branch
+CRoot_Branch_GetMother(CRoot_Branch self)
+{
+  return (CRoot_Branch)(((TBranch*)self)->GetMother());
+}
+
 CRoot_ObjArray
 CRoot_Branch_GetListOfLeaves(CRoot_Branch self)
 {
   return (CRoot_ObjArray)((TBranch*)self)->GetListOfLeaves();
 }
 
+int32_t
+CRoot_Branch_GetBasketSize(CRoot_Branch self)
+{
+  return ((TBranch*)self)->GetBasketSize();
+}
+
+int64_t
+CRoot_Branch_GetEntries(CRoot_Branch self)
+{
+  return ((TBranch*)self)->GetEntries();
+}
+
+int64_t
+CRoot_Branch_GetTotBytes(CRoot_Branch self, CRoot_Option *option)
+{
+  return ((TBranch*)self)->GetTotBytes((Option_t*)option);
+}
+
+int64_t
+CRoot_Branch_GetZipBytes(CRoot_Branch self, CRoot_Option *option)
+{
+  return ((TBranch*)self)->GetZipBytes((Option_t*)option);
+}
+
+int32_t
+CRoot_Branch_GetReadBasket(CRoot_Branch self)
+{
+  return ((TBranch*)self)->GetReadBasket();
+}
+
+int32_t
+CRoot_Branch_GetWriteBasket(CRoot_Branch self)
+{
+  return ((TBranch*)self)->GetWriteBasket();
+}
+
+void
+CRoot_Branch_SetBasketSize(CRoot_Branch self, int32_t bufsize)
+{
+  ((TBranch*)self)->SetBasketSize(bufsize);
+}
+
+int32_t
+CRoot_Branch_GetCompressionSettings(CRoot_Branch self)
+{
+  return ((TBranch*)self)->GetCompressionSettings();
+}
+
+void
+CRoot_Branch_SetCompressionSettings(CRoot_Branch self, int32_t settings)
+{
+  ((TBranch*)self)->SetCompressionSettings(settings);
+}
+
 const char*
 CRoot_Branch_GetClassName(CRoot_Branch self)
 {
@@ -446,6 +816,30 @@ CRoot_File_WriteBuffer(CRoot_File self,
   return ((TFile*)self)->WriteBuffer(buf, len);
 }
 
+int32_t
+CRoot_File_GetCompressionSettings(CRoot_File self)
+{
+  return ((TFile*)self)->GetCompressionSettings();
+}
+
+void
+CRoot_File_SetCompressionSettings(CRoot_File self, int32_t settings)
+{
+  ((TFile*)self)->SetCompressionSettings(settings);
+}
+
+int64_t
+CRoot_File_GetBytesRead(CRoot_File self)
+{
+  return ((TFile*)self)->GetBytesRead();
+}
+
+int32_t
+CRoot_File_GetReadCalls(CRoot_File self)
+{
+  return ((TFile*)self)->GetReadCalls();
+}
+
 int32_t
 CRoot_File_Write(CRoot_File self, 
                  const char *name, int32_t opt, int32_t bufsiz)
@@ -453,15 +847,103 @@ CRoot_File_Write(CRoot_File self,
   return ((TFile*)self)->Write(name, opt, bufsiz);
 }
 
+/* TDirectory */
+CRoot_Bool
+CRoot_Directory_cd(CRoot_Directory self, const char *path)
+{
+  return (CRoot_Bool)(((TDirectory*)self)->cd(path));
+}
+
+CRoot_Object
+CRoot_Directory_Get(CRoot_Directory self, const char *namecycle)
+{
+  return (TObject*)((TDirectory*)self)->Get(namecycle);
+}
+
+int32_t
+CRoot_Directory_GetNkeys(CRoot_Directory self)
+{
+  TList *keys = ((TDirectory*)self)->GetListOfKeys();
+  if (keys == 0) {
+    return 0;
+  }
+  return keys->GetSize();
+}
+
+CRoot_Directory
+CRoot_Directory_mkdir(CRoot_Directory self, const char *name, const char *title)
+{
+  return (CRoot_Directory)(((TDirectory*)self)->mkdir(name, title));
+}
+
+CRoot_Bool
+CRoot_Directory_GetKey(CRoot_Directory self, int32_t i,
+                       const char **name, const char **title,
+                       const char **classname, int16_t *cycle,
+                       int32_t *nbytes, int32_t *objlen)
+{
+  TList *keys = ((TDirectory*)self)->GetListOfKeys();
+  if (keys == 0 || i < 0 || i >= keys->GetSize()) {
+    return (CRoot_Bool)0;
+  }
+  TKey *key = (TKey*)keys->At(i);
+  *name = key->GetName();
+  *title = key->GetTitle();
+  *classname = key->GetClassName();
+  *cycle = key->GetCycle();
+  *nbytes = key->GetNbytes();
+  *objlen = key->GetObjlen();
+  return (CRoot_Bool)1;
+}
+
 /* TRandom */
 CRoot_Random CRoot_gRandom = (CRoot_Random)gRandom;
 
+CRoot_Random
+CRoot_Random3_new(uint32_t seed)
+{
+  TRandom3 *self = new TRandom3(seed);
+  return (CRoot_Random)self;
+}
+
+void
+CRoot_Random_delete(CRoot_Random self)
+{
+  TRandom *r = (TRandom*)self;
+  delete r;
+  self = 0;
+}
+
+void
+CRoot_Random_SetSeed(CRoot_Random self, uint32_t seed)
+{
+  ((TRandom*)self)->SetSeed(seed);
+}
+
+uint32_t
+CRoot_Random_GetSeed(CRoot_Random self)
+{
+  return ((TRandom*)self)->GetSeed();
+}
+
+double
+CRoot_Random_BreitWigner(CRoot_Random self, double mean, double gamma)
+{
+  return ((TRandom*)self)->BreitWigner(mean, gamma);
+}
+
 int32_t
 CRoot_Random_Binomial(CRoot_Random self, int32_t ntot, double prob)
 {
   return ((TRandom*)self)->Binomial(ntot, prob);
 }
 
+double
+CRoot_Random_Exp(CRoot_Random self, double tau)
+{
+  return ((TRandom*)self)->Exp(tau);
+}
+
 double
 CRoot_Random_Gaus(CRoot_Random self,
                   double mean, double sigma)
@@ -469,6 +951,24 @@ CRoot_Random_Gaus(CRoot_Random self,
   return ((TRandom*)self)->Gaus(mean, sigma);
 }
 
+uint32_t
+CRoot_Random_Integer(CRoot_Random self, uint32_t imax)
+{
+  return ((TRandom*)self)->Integer(imax);
+}
+
+double
+CRoot_Random_Landau(CRoot_Random self, double mean, double sigma)
+{
+  return ((TRandom*)self)->Landau(mean, sigma);
+}
+
+int32_t
+CRoot_Random_Poisson(CRoot_Random self, double mean)
+{
+  return ((TRandom*)self)->Poisson(mean);
+}
+
 void
 CRoot_Random_Rannorf(CRoot_Random self,
                      float *a, float *b)
@@ -490,6 +990,26 @@ CRoot_Random_Rndm(CRoot_Random self,
   return ((TRandom*)self)->Rndm(i);
 }
 
+void
+CRoot_Random_RndmArray(CRoot_Random self,
+                       int32_t n, double *array)
+{
+  ((TRandom*)self)->RndmArray(n, array);
+}
+
+void
+CRoot_Random_Sphere(CRoot_Random self,
+                    double *x, double *y, double *z, double r)
+{
+  ((TRandom*)self)->Sphere(*x, *y, *z, r);
+}
+
+double
+CRoot_Random_Uniform(CRoot_Random self, double x1, double x2)
+{
+  return ((TRandom*)self)->Uniform(x1, x2);
+}
+
 /* TMath */
 
 
@@ -651,6 +1171,98 @@ CRoot_Math_Log10(double x)
   return TMath::Log10(x);
 }
 
+/* TMath statistics */
+
+double
+CRoot_Math_Gaus(double x, double mean, double sigma, CRoot_Bool norm)
+{
+  return TMath::Gaus(x, mean, sigma, (Bool_t)norm);
+}
+
+double
+CRoot_Math_Landau(double x, double mpv, double sigma, CRoot_Bool norm)
+{
+  return TMath::Landau(x, mpv, sigma, (Bool_t)norm);
+}
+
+double
+CRoot_Math_BreitWigner(double x, double mean, double gamma)
+{
+  return TMath::BreitWigner(x, mean, gamma);
+}
+
+double
+CRoot_Math_Poisson(double x, double par)
+{
+  return TMath::Poisson(x, par);
+}
+
+double
+CRoot_Math_PoissonI(double x, double par)
+{
+  return TMath::PoissonI(x, par);
+}
+
+double
+CRoot_Math_Prob(double chi2, int32_t ndf)
+{
+  return TMath::Prob(chi2, ndf);
+}
+
+double
+CRoot_Math_Erf(double x)
+{
+  return TMath::Erf(x);
+}
+
+double
+CRoot_Math_Erfc(double x)
+{
+  return TMath::Erfc(x);
+}
+
+double
+CRoot_Math_ErfInverse(double x)
+{
+  return TMath::ErfInverse(x);
+}
+
+double
+CRoot_Math_ErfcInverse(double x)
+{
+  return TMath::ErfcInverse(x);
+}
+
+double
+CRoot_Math_Freq(double x)
+{
+  return TMath::Freq(x);
+}
+
+double
+CRoot_Math_ChisquareQuantile(double p, double ndf)
+{
+  return TMath::ChisquareQuantile(p, ndf);
+}
+
+double
+CRoot_Math_Student(double t, double ndf)
+{
+  return TMath::Student(t, ndf);
+}
+
+double
+CRoot_Math_StudentI(double t, double ndf)
+{
+  return TMath::StudentI(t, ndf);
+}
+
+double
+CRoot_Math_StudentQuantile(double p, double ndf, CRoot_Bool lower_tail)
+{
+  return TMath::StudentQuantile(p, ndf, (Bool_t)lower_tail);
+}
+
 
 /* -- CINT-API -- */
 #if 1
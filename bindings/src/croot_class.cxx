@@ -7,3 +7,15 @@ CRoot_Class_GetClass(const char *name)
 {
   return (CRoot_Class)(TClass::GetClass(name));
 }
+
+void*
+CRoot_Class_New(CRoot_Class self)
+{
+  return ((TClass*)self)->New();
+}
+
+void
+CRoot_Class_Destructor(CRoot_Class self, void *obj)
+{
+  ((TClass*)self)->Destructor(obj);
+}
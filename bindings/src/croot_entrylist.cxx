@@ -0,0 +1,82 @@
+#include "croot/croot.h"
+
+#include "TDirectory.h"
+#include "TEntryList.h"
+#include "TEventList.h"
+#include "TString.h"
+#include "TTree.h"
+
+/* TEntryList */
+CRoot_EntryList
+CRoot_EntryList_new(const char *name, const char *title, CRoot_Tree tree)
+{
+  TEntryList *self = 0;
+  if (tree) {
+    self = new TEntryList(name, title, (TTree*)tree);
+  } else {
+    self = new TEntryList(name, title);
+  }
+  return (CRoot_EntryList)self;
+}
+
+CRoot_EntryList
+CRoot_EntryList_newFromEventList(CRoot_EventList elist)
+{
+  TEntryList *self = new TEntryList(*(const TEventList*)elist);
+  return (CRoot_EntryList)self;
+}
+
+CRoot_EntryList
+CRoot_EntryList_newFromSelection(CRoot_Tree tree,
+                                 const char *name, const char *selection)
+{
+  TTree *t = (TTree*)tree;
+  TString varexp = TString::Format(">>%s", name);
+  Long64_t n = t->Draw(varexp, selection, "entrylist goff");
+  if (n < 0) {
+    return 0;
+  }
+  // TTree::Draw stores the list in the current directory.
+  TEntryList *self = dynamic_cast<TEntryList*>(gDirectory->Get(name));
+  return (CRoot_EntryList)self;
+}
+
+void
+CRoot_EntryList_delete(CRoot_EntryList self)
+{
+  TEntryList *elist = (TEntryList*)self;
+  delete elist;
+  self = 0;
+}
+
+CRoot_Bool
+CRoot_EntryList_Enter(CRoot_EntryList self, int64_t entry, CRoot_Tree tree)
+{
+  return (CRoot_Bool)(((TEntryList*)self)->Enter(entry, (TTree*)tree));
+}
+
+int32_t
+CRoot_EntryList_Contains(CRoot_EntryList self, int64_t entry, CRoot_Tree tree)
+{
+  return ((TEntryList*)self)->Contains(entry, (TTree*)tree);
+}
+
+int64_t
+CRoot_EntryList_GetEntry(CRoot_EntryList self, int32_t index)
+{
+  return ((TEntryList*)self)->GetEntry(index);
+}
+
+int64_t
+CRoot_EntryList_GetN(CRoot_EntryList self)
+{
+  return ((TEntryList*)self)->GetN();
+}
+
+void
+CRoot_EntryList_SetTree(CRoot_EntryList self, CRoot_Tree tree)
+{
+  ((TEntryList*)self)->SetTree((const TTree*)tree);
+}
+
+// EOF
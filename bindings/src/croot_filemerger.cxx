@@ -0,0 +1,71 @@
+#include "croot/croot.h"
+
+#include "RVersion.h"
+#include "TFileMerger.h"
+
+/* TFileMerger */
+CRoot_FileMerger
+CRoot_FileMerger_new(CRoot_Bool isLocal)
+{
+  return (CRoot_FileMerger)(new TFileMerger((Bool_t)isLocal));
+}
+
+void
+CRoot_FileMerger_delete(CRoot_FileMerger self)
+{
+  TFileMerger *m = (TFileMerger*)self;
+  delete m;
+  self = 0;
+}
+
+CRoot_Bool
+CRoot_FileMerger_OutputFile(CRoot_FileMerger self, const char *url,
+                            CRoot_Bool force, int32_t compress)
+{
+  return (CRoot_Bool)(((TFileMerger*)self)->OutputFile(url, (Bool_t)force, compress));
+}
+
+CRoot_Bool
+CRoot_FileMerger_AddFile(CRoot_FileMerger self, const char *url)
+{
+  return (CRoot_Bool)(((TFileMerger*)self)->AddFile(url, kFALSE));
+}
+
+CRoot_Bool
+CRoot_FileMerger_AddObjectNames(CRoot_FileMerger self, const char *names)
+{
+#if ROOT_VERSION_CODE >= ROOT_VERSION(6,8,0)
+  ((TFileMerger*)self)->AddObjectNames(names);
+  return (CRoot_Bool)1;
+#else
+  return (CRoot_Bool)0;
+#endif
+}
+
+void
+CRoot_FileMerger_SetFastMethod(CRoot_FileMerger self, CRoot_Bool fast)
+{
+  ((TFileMerger*)self)->SetFastMethod((Bool_t)fast);
+}
+
+void
+CRoot_FileMerger_SetPrintLevel(CRoot_FileMerger self, int32_t level)
+{
+  ((TFileMerger*)self)->SetPrintLevel(level);
+}
+
+CRoot_Bool
+CRoot_FileMerger_Merge(CRoot_FileMerger self)
+{
+  TFileMerger *m = (TFileMerger*)self;
+#if ROOT_VERSION_CODE >= ROOT_VERSION(6,8,0)
+  if (m->GetObjectNames() != 0 && m->GetObjectNames()[0] != '\0') {
+    return (CRoot_Bool)(m->PartialMerge(TFileMerger::kAll |
+                                        TFileMerger::kRegular |
+                                        TFileMerger::kOnlyListed));
+  }
+#endif
+  return (CRoot_Bool)(m->Merge());
+}
+
+// EOF
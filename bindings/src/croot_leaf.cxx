@@ -5,6 +5,23 @@
 #include "TLeafF.h"
 #include "TLeafD.h"
 #include "TLeafO.h"
+#include "TBranchElement.h"
+
+#include <string.h>
+#include <string>
+#include <vector>
+
+namespace {
+  template <typename T>
+  CRoot_Bool
+  croot_vector_data(void *obj, void **data, int64_t *len)
+  {
+    std::vector<T> *v = (std::vector<T>*)obj;
+    *len = v->size();
+    *data = v->empty() ? 0 : (void*)&(*v)[0];
+    return (CRoot_Bool)1;
+  }
+}
 
 /* TLeaf */
 CRoot_Branch
@@ -13,6 +30,12 @@ CRoot_Leaf_GetBranch(CRoot_Leaf self)
   return (CRoot_Branch)(((TLeaf*)self)->GetBranch());
 }
 
+int
+CRoot_Leaf_GetLen(CRoot_Leaf self)
+{
+  return ((TLeaf*)self)->GetLen();
+}
+
 int
 CRoot_Leaf_GetLenStatic(CRoot_Leaf self)
 {
@@ -43,6 +66,63 @@ CRoot_Leaf_SetAddress(CRoot_Leaf self,void* addr)
   ((TLeaf*)self)->SetAddress(addr);
 }
 
+CRoot_Bool
+CRoot_Leaf_GetVectorData(CRoot_Leaf self, void **data, int64_t *len)
+{
+  *data = 0;
+  *len = 0;
+  TBranchElement *br = dynamic_cast<TBranchElement*>(((TLeaf*)self)->GetBranch());
+  if (br == 0 || br->GetObject() == 0) {
+    return (CRoot_Bool)0;
+  }
+  void *obj = br->GetObject();
+  const char *cls = br->GetClassName();
+
+#define CROOT_VECTOR_DATA(T) \
+  if (strcmp(cls, "vector<" #T ">") == 0) { \
+    return croot_vector_data<T>(obj, data, len); \
+  }
+
+  CROOT_VECTOR_DATA(char)
+  CROOT_VECTOR_DATA(unsigned char)
+  CROOT_VECTOR_DATA(short)
+  CROOT_VECTOR_DATA(unsigned short)
+  CROOT_VECTOR_DATA(int)
+  CROOT_VECTOR_DATA(unsigned int)
+  CROOT_VECTOR_DATA(long)
+  CROOT_VECTOR_DATA(unsigned long)
+  CROOT_VECTOR_DATA(Long64_t)
+  CROOT_VECTOR_DATA(ULong64_t)
+  CROOT_VECTOR_DATA(long long)
+  CROOT_VECTOR_DATA(unsigned long long)
+  CROOT_VECTOR_DATA(float)
+  CROOT_VECTOR_DATA(double)
+  CROOT_VECTOR_DATA(Double32_t)
+
+#undef CROOT_VECTOR_DATA
+
+  return (CRoot_Bool)0;
+}
+
+CRoot_Bool
+CRoot_Leaf_GetStringData(CRoot_Leaf self, const char **data, int64_t *len)
+{
+  *data = 0;
+  *len = 0;
+  TBranchElement *br = dynamic_cast<TBranchElement*>(((TLeaf*)self)->GetBranch());
+  if (br == 0 || br->GetObject() == 0) {
+    return (CRoot_Bool)0;
+  }
+  const char *cls = br->GetClassName();
+  if (strcmp(cls, "string") != 0 && strcmp(cls, "std::string") != 0) {
+    return (CRoot_Bool)0;
+  }
+  std::string *str = (std::string*)br->GetObject();
+  *data = str->data();
+  *len = str->size();
+  return (CRoot_Bool)1;
+}
+
 /* TLeafI */
 double
 CRoot_LeafI_GetValue(CRoot_LeafI self, int idx)
@@ -0,0 +1,235 @@
+#include "croot/croot.h"
+
+#include "Math/Minimizer.h"
+#include "Math/Factory.h"
+#include "Math/Functor.h"
+
+namespace {
+  // adapts a C callback to the functor interface ROOT::Math::Functor expects
+  struct croot_minimizer_fcn {
+    CRoot_Minimizer_Fcn fcn;
+    int64_t ctx;
+    int32_t npar;
+
+    double operator()(const double *x) const
+    {
+      return fcn(ctx, npar, const_cast<double*>(x));
+    }
+  };
+}
+
+/* ROOT::Math::Minimizer */
+CRoot_Minimizer
+CRoot_Minimizer_new(const char *type, const char *algo)
+{
+  ROOT::Math::Minimizer *self = ROOT::Math::Factory::CreateMinimizer(type, algo);
+  return (CRoot_Minimizer)self;
+}
+
+void
+CRoot_Minimizer_delete(CRoot_Minimizer self)
+{
+  ROOT::Math::Minimizer *m = (ROOT::Math::Minimizer*)self;
+  delete m;
+  self = 0;
+}
+
+void
+CRoot_Minimizer_SetFunction(CRoot_Minimizer self,
+                            CRoot_Minimizer_Fcn fcn, int64_t ctx,
+                            int32_t npar)
+{
+  croot_minimizer_fcn f = {fcn, ctx, npar};
+  // the minimizer clones the functor, so a temporary is fine.
+  ROOT::Math::Functor functor(f, npar);
+  ((ROOT::Math::Minimizer*)self)->SetFunction(functor);
+}
+
+CRoot_Bool
+CRoot_Minimizer_SetVariable(CRoot_Minimizer self,
+                            int32_t ivar, const char *name,
+                            double val, double step)
+{
+  return (CRoot_Bool)(((ROOT::Math::Minimizer*)self)->SetVariable(ivar, name, val, step));
+}
+
+CRoot_Bool
+CRoot_Minimizer_SetLimitedVariable(CRoot_Minimizer self,
+                                   int32_t ivar, const char *name,
+                                   double val, double step,
+                                   double lower, double upper)
+{
+  return (CRoot_Bool)(((ROOT::Math::Minimizer*)self)->SetLimitedVariable(ivar, name, val, step,
+                                                                         lower, upper));
+}
+
+CRoot_Bool
+CRoot_Minimizer_SetLowerLimitedVariable(CRoot_Minimizer self,
+                                        int32_t ivar, const char *name,
+                                        double val, double step,
+                                        double lower)
+{
+  return (CRoot_Bool)(((ROOT::Math::Minimizer*)self)->SetLowerLimitedVariable(ivar, name, val, step,
+                                                                              lower));
+}
+
+CRoot_Bool
+CRoot_Minimizer_SetUpperLimitedVariable(CRoot_Minimizer self,
+                                        int32_t ivar, const char *name,
+                                        double val, double step,
+                                        double upper)
+{
+  return (CRoot_Bool)(((ROOT::Math::Minimizer*)self)->SetUpperLimitedVariable(ivar, name, val, step,
+                                                                              upper));
+}
+
+CRoot_Bool
+CRoot_Minimizer_SetFixedVariable(CRoot_Minimizer self,
+                                 int32_t ivar, const char *name,
+                                 double val)
+{
+  return (CRoot_Bool)(((ROOT::Math::Minimizer*)self)->SetFixedVariable(ivar, name, val));
+}
+
+CRoot_Bool
+CRoot_Minimizer_FixVariable(CRoot_Minimizer self, int32_t ivar)
+{
+  return (CRoot_Bool)(((ROOT::Math::Minimizer*)self)->FixVariable(ivar));
+}
+
+CRoot_Bool
+CRoot_Minimizer_ReleaseVariable(CRoot_Minimizer self, int32_t ivar)
+{
+  return (CRoot_Bool)(((ROOT::Math::Minimizer*)self)->ReleaseVariable(ivar));
+}
+
+CRoot_Bool
+CRoot_Minimizer_IsFixedVariable(CRoot_Minimizer self, int32_t ivar)
+{
+  return (CRoot_Bool)(((ROOT::Math::Minimizer*)self)->IsFixedVariable(ivar));
+}
+
+void
+CRoot_Minimizer_SetPrintLevel(CRoot_Minimizer self, int32_t level)
+{
+  ((ROOT::Math::Minimizer*)self)->SetPrintLevel(level);
+}
+
+void
+CRoot_Minimizer_SetStrategy(CRoot_Minimizer self, int32_t strategy)
+{
+  ((ROOT::Math::Minimizer*)self)->SetStrategy(strategy);
+}
+
+void
+CRoot_Minimizer_SetTolerance(CRoot_Minimizer self, double tol)
+{
+  ((ROOT::Math::Minimizer*)self)->SetTolerance(tol);
+}
+
+void
+CRoot_Minimizer_SetErrorDef(CRoot_Minimizer self, double up)
+{
+  ((ROOT::Math::Minimizer*)self)->SetErrorDef(up);
+}
+
+void
+CRoot_Minimizer_SetMaxFunctionCalls(CRoot_Minimizer self, uint32_t maxfcn)
+{
+  ((ROOT::Math::Minimizer*)self)->SetMaxFunctionCalls(maxfcn);
+}
+
+void
+CRoot_Minimizer_SetMaxIterations(CRoot_Minimizer self, uint32_t maxiter)
+{
+  ((ROOT::Math::Minimizer*)self)->SetMaxIterations(maxiter);
+}
+
+CRoot_Bool
+CRoot_Minimizer_Minimize(CRoot_Minimizer self)
+{
+  return (CRoot_Bool)(((ROOT::Math::Minimizer*)self)->Minimize());
+}
+
+CRoot_Bool
+CRoot_Minimizer_Hesse(CRoot_Minimizer self)
+{
+  return (CRoot_Bool)(((ROOT::Math::Minimizer*)self)->Hesse());
+}
+
+CRoot_Bool
+CRoot_Minimizer_GetMinosError(CRoot_Minimizer self, int32_t ivar,
+                              double *errlow, double *errup)
+{
+  return (CRoot_Bool)(((ROOT::Math::Minimizer*)self)->GetMinosError(ivar, *errlow, *errup));
+}
+
+int32_t
+CRoot_Minimizer_Status(CRoot_Minimizer self)
+{
+  return ((ROOT::Math::Minimizer*)self)->Status();
+}
+
+int32_t
+CRoot_Minimizer_CovMatrixStatus(CRoot_Minimizer self)
+{
+  return ((ROOT::Math::Minimizer*)self)->CovMatrixStatus();
+}
+
+double
+CRoot_Minimizer_MinValue(CRoot_Minimizer self)
+{
+  return ((ROOT::Math::Minimizer*)self)->MinValue();
+}
+
+double
+CRoot_Minimizer_Edm(CRoot_Minimizer self)
+{
+  return ((ROOT::Math::Minimizer*)self)->Edm();
+}
+
+uint32_t
+CRoot_Minimizer_NCalls(CRoot_Minimizer self)
+{
+  return ((ROOT::Math::Minimizer*)self)->NCalls();
+}
+
+uint32_t
+CRoot_Minimizer_NDim(CRoot_Minimizer self)
+{
+  return ((ROOT::Math::Minimizer*)self)->NDim();
+}
+
+void
+CRoot_Minimizer_X(CRoot_Minimizer self, double *x)
+{
+  ROOT::Math::Minimizer *m = (ROOT::Math::Minimizer*)self;
+  const double *v = m->X();
+  if (v == 0) {
+    return;
+  }
+  for (unsigned int i = 0; i != m->NDim(); ++i) {
+    x[i] = v[i];
+  }
+}
+
+void
+CRoot_Minimizer_Errors(CRoot_Minimizer self, double *errs)
+{
+  ROOT::Math::Minimizer *m = (ROOT::Math::Minimizer*)self;
+  const double *v = m->Errors();
+  if (v == 0) {
+    return;
+  }
+  for (unsigned int i = 0; i != m->NDim(); ++i) {
+    errs[i] = v[i];
+  }
+}
+
+double
+CRoot_Minimizer_CovMatrix(CRoot_Minimizer self, int32_t i, int32_t j)
+{
+  return ((ROOT::Math::Minimizer*)self)->CovMatrix(i, j);
+}
+
+// EOF
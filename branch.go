@@ -15,9 +15,20 @@ import (
 type Branch interface {
 	Object
 	GetAddress() uintptr
+	GetBasketSize() int
 	GetClassName() string
+	GetCompressionSettings() int
+	GetEntries() int64
+	GetListOfBranches() []Branch
 	GetListOfLeaves() []Leaf
 	GetLeaf(n string) Leaf
+	GetMother() Branch
+	GetReadBasket() int
+	GetTotBytes(option Option) int64
+	GetWriteBasket() int
+	GetZipBytes(option Option) int64
+	SetBasketSize(bufsize int)
+	SetCompressionSettings(settings int)
 }
 
 type branch_impl struct {
@@ -68,11 +79,38 @@ func (b *branch_impl) GetAddress() uintptr {
 // 	return uintptr(unsafe.Pointer(C.CRoot_Branch_GetObject(b.c)))
 // }
 
+func (b *branch_impl) GetBasketSize() int {
+	return int(C.CRoot_Branch_GetBasketSize(b.c))
+}
+
 func (b *branch_impl) GetClassName() string {
 	c_str := C.CRoot_Branch_GetClassName(b.c)
 	return C.GoString(c_str)
 }
 
+func (b *branch_impl) GetCompressionSettings() int {
+	return int(C.CRoot_Branch_GetCompressionSettings(b.c))
+}
+
+func (b *branch_impl) GetEntries() int64 {
+	return int64(C.CRoot_Branch_GetEntries(b.c))
+}
+
+// GetListOfBranches returns the sub-branches of the branch.
+func (b *branch_impl) GetListOfBranches() []Branch {
+	c := C.CRoot_Branch_GetListOfBranches(b.c)
+	if c == nil {
+		return nil
+	}
+	objs := objarray_impl{c: c}
+	branches := make([]Branch, objs.GetEntries())
+	for i := range branches {
+		c_br := C.CRoot_ObjArray_At(objs.c, C.int64_t(i))
+		branches[i] = &branch_impl{c: (C.CRoot_Branch)(c_br)}
+	}
+	return branches
+}
+
 func (b *branch_impl) GetListOfLeaves() []Leaf {
 	c := C.CRoot_Branch_GetListOfLeaves(b.c)
 	objs := objarray_impl{c: c}
@@ -95,6 +133,53 @@ func (b *branch_impl) GetLeaf(name string) Leaf {
 	return &leaf_impl{c: c}
 }
 
+// GetMother returns the top-level branch holding this branch.
+func (b *branch_impl) GetMother() Branch {
+	c := C.CRoot_Branch_GetMother(b.c)
+	if c == nil {
+		return nil
+	}
+	return &branch_impl{c: c}
+}
+
+// GetReadBasket returns the index of the basket currently read.
+func (b *branch_impl) GetReadBasket() int {
+	return int(C.CRoot_Branch_GetReadBasket(b.c))
+}
+
+// GetTotBytes returns the uncompressed size (in bytes) of the branch.
+// With the "*" option, the sizes of its sub-branches are included.
+func (b *branch_impl) GetTotBytes(option Option) int64 {
+	c_option := C.CString(string(option))
+	defer C.free(unsafe.Pointer(c_option))
+	return int64(C.CRoot_Branch_GetTotBytes(b.c, (*C.CRoot_Option)(c_option)))
+}
+
+// GetWriteBasket returns the index of the basket currently written, ie.
+// the number of baskets written out so far.
+func (b *branch_impl) GetWriteBasket() int {
+	return int(C.CRoot_Branch_GetWriteBasket(b.c))
+}
+
+// GetZipBytes returns the compressed size (in bytes) of the branch.
+// With the "*" option, the sizes of its sub-branches are included.
+func (b *branch_impl) GetZipBytes(option Option) int64 {
+	c_option := C.CString(string(option))
+	defer C.free(unsafe.Pointer(c_option))
+	return int64(C.CRoot_Branch_GetZipBytes(b.c, (*C.CRoot_Option)(c_option)))
+}
+
+// SetBasketSize sets the size (in bytes) of the buffers of the branch.
+func (b *branch_impl) SetBasketSize(bufsize int) {
+	C.CRoot_Branch_SetBasketSize(b.c, C.int32_t(bufsize))
+}
+
+// SetCompressionSettings sets the compression settings of the branch,
+// overriding the ones of its file (see CompressionSettings).
+func (b *branch_impl) SetCompressionSettings(settings int) {
+	C.CRoot_Branch_SetCompressionSettings(b.c, C.int32_t(settings))
+}
+
 func init() {
 	cnvmap["TBranch"] = func(o c_object) Object {
 		return &branch_impl{c: (C.CRoot_Branch)(o.cptr())}
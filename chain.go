@@ -0,0 +1,66 @@
+package croot
+
+// #include "croot/croot.h"
+//
+// #include <stdlib.h>
+// #include <string.h>
+import "C"
+
+import (
+	"unsafe"
+)
+
+// Chain is a collection of files containing Tree objects
+type Chain interface {
+	Tree
+
+	Add(name string, nentries int64) int
+	AddFile(name string, nentries int64, tname string) int
+}
+
+type chain_impl struct {
+	tree_impl
+}
+
+func NewChain(name, title string) Chain {
+	c_name := C.CString(name)
+	defer C.free(unsafe.Pointer(c_name))
+	c_title := C.CString(title)
+	defer C.free(unsafe.Pointer(c_title))
+	c := C.CRoot_Chain_new(c_name, c_title)
+	b := make(map[string]*gobranch)
+	return &chain_impl{tree_impl{c: (C.CRoot_Tree)(c), branches: b, treenum: -1}}
+}
+
+func (ch *chain_impl) as_tchain() C.CRoot_Chain {
+	return (C.CRoot_Chain)(ch.c)
+}
+
+func (ch *chain_impl) Delete() {
+	C.CRoot_Chain_delete(ch.as_tchain())
+	ch.c = nil
+	ch.branches = nil
+}
+
+func (ch *chain_impl) Add(name string, nentries int64) int {
+	c_name := C.CString(name)
+	defer C.free(unsafe.Pointer(c_name))
+	return int(C.CRoot_Chain_Add(ch.as_tchain(), c_name, C.int64_t(nentries)))
+}
+
+func (ch *chain_impl) AddFile(name string, nentries int64, tname string) int {
+	c_name := C.CString(name)
+	defer C.free(unsafe.Pointer(c_name))
+	c_tname := C.CString(tname)
+	defer C.free(unsafe.Pointer(c_tname))
+	return int(C.CRoot_Chain_AddFile(ch.as_tchain(), c_name, C.int64_t(nentries), c_tname))
+}
+
+func init() {
+	cnvmap["TChain"] = func(o c_object) Object {
+		b := make(map[string]*gobranch)
+		return &chain_impl{tree_impl{c: (C.CRoot_Tree)(o.cptr()), branches: b, treenum: -1}}
+	}
+}
+
+// EOF
@@ -0,0 +1,148 @@
+// go-croot-convert converts a ROOT tree into flat formats:
+//
+//	$ go-croot-convert [options] file.root
+//	$ go-croot-convert -t tree -b x,y -sel "x>0" -o out.csv file.root
+//	$ go-croot-convert -format ndjson -first 10 -n 100 file.root > out.json
+//	$ go-croot-convert -b x,y -o out.npz file.root
+//	$ go-croot-convert -format npy -o arrays-dir file.root
+//	$ go-croot-convert -o out.arrow file.root
+//
+// the entries are converted one at a time, so trees don't need to fit in
+// memory.
+// supported formats are CSV (with -explode to write a row per element of
+// the arrays), newline-delimited JSON, NumPy arrays (a .npy file per
+// array in the -o directory, or a .npz archive) and Apache Arrow IPC files
+// (Feather v2).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-hep/croot"
+)
+
+var (
+	fname    = flag.String("f", "", "ROOT file to convert")
+	tname    = flag.String("t", "", "ROOT tree to convert (default: the first tree of the file)")
+	branches = flag.String("b", "", "comma-separated list of branches to convert (default: all)")
+	sel      = flag.String("sel", "", "selection entries have to pass (e.g. \"x>0 && n==2\")")
+	first    = flag.Int64("first", 0, "first entry to convert")
+	nentries = flag.Int64("n", -1, "number of entries to convert (default: all)")
+	oname    = flag.String("o", "", "output file, or directory for the npy format (default: standard output)")
+	format   = flag.String("format", "", "output format (csv, ndjson, npy, npz or arrow, default: from the output file extension or csv)")
+	explode  = flag.Bool("explode", false, "write a CSV row per element of the arrays instead of flattening them")
+)
+
+func main() {
+	flag.Parse()
+
+	if *fname == "" && flag.NArg() > 0 {
+		*fname = flag.Arg(0)
+	}
+
+	if *fname == "" {
+		fmt.Fprintf(os.Stderr, "**error** you have to give a (valid) path to a ROOT file\n")
+		os.Exit(1)
+	}
+
+	if *format == "" {
+		switch strings.ToLower(filepath.Ext(*oname)) {
+		case ".json", ".ndjson", ".jsonl":
+			*format = "ndjson"
+		case ".npz":
+			*format = "npz"
+		case ".arrow", ".feather":
+			*format = "arrow"
+		default:
+			*format = "csv"
+		}
+	}
+
+	var export func(w io.Writer, t croot.Tree, opts croot.ExportOptions) (int64, error)
+	switch *format {
+	case "csv":
+		export = croot.ExportCSV
+	case "ndjson", "json":
+		export = croot.ExportNDJSON
+	case "npz":
+		export = croot.ExportNPZ
+	case "arrow", "feather":
+		export = croot.ExportArrow
+	case "npy":
+		if *oname == "" {
+			fmt.Fprintf(os.Stderr, "**error** the npy format needs an output directory (-o)\n")
+			os.Exit(1)
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "**error** invalid output format [%s]\n", *format)
+		os.Exit(1)
+	}
+
+	f, err := croot.OpenFile(*fname, "read", "go-croot-convert-file", 1, 0)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "**error** %v\n", err)
+		os.Exit(1)
+	}
+	defer f.Close("")
+
+	if *tname == "" {
+		for _, k := range f.GetListOfKeys() {
+			if k.ClassName == "TTree" || k.ClassName == "TNtuple" || k.ClassName == "TNtupleD" {
+				*tname = k.Name
+				break
+			}
+		}
+	}
+	tree := f.GetTree(*tname)
+	if tree == nil {
+		fmt.Fprintf(os.Stderr, "**error** no such tree [%s]\n", *tname)
+		os.Exit(1)
+	}
+
+	opts := croot.ExportOptions{
+		Selection: *sel,
+		First:     *first,
+		N:         *nentries,
+		Explode:   *explode,
+	}
+	for _, b := range strings.Split(*branches, ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			opts.Branches = append(opts.Branches, b)
+		}
+	}
+
+	if *format == "npy" {
+		n, err := croot.ExportNPY(*oname, tree, opts)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "**error** %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, ":: converted %d entries into [%s]\n", n, *oname)
+		return
+	}
+
+	var out io.Writer = os.Stdout
+	if *oname != "" {
+		o, err := os.Create(*oname)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "**error** %v\n", err)
+			os.Exit(1)
+		}
+		defer o.Close()
+		out = o
+	}
+
+	n, err := export(out, tree, opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "**error** %v\n", err)
+		os.Exit(1)
+	}
+	if *oname != "" {
+		fmt.Fprintf(os.Stderr, ":: converted %d entries into [%s]\n", n, *oname)
+	}
+}
@@ -0,0 +1,270 @@
+// go-croot-dump prints the values of the entries of a ROOT tree, like
+// ROOT's TTree::Scan:
+//
+//	$ go-croot-dump [options] file.root
+//	$ go-croot-dump -t tree -b x,y -first 10 -n 5 -format json file.root
+//
+// the values can be printed as a table, one JSON object per line or
+// Go-syntax map[string]interface{} composite literals.
+//
+// the values are read with a croot.Reader, whose columns are flat: arrays
+// and std::vectors of builtins are printed as lists, the leaves of structs
+// as "branch.leaf" columns and the members of split objects as the columns
+// of their sub-branches.
+// Unsplit objects are skipped, with a warning.
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/go-hep/croot"
+)
+
+var (
+	fname    = flag.String("f", "", "ROOT file to inspect")
+	tname    = flag.String("t", "", "ROOT tree to dump (default: the first tree of the file)")
+	branches = flag.String("b", "", "comma-separated list of branches to dump (default: all)")
+	first    = flag.Int64("first", 0, "first entry to dump")
+	nentries = flag.Int64("n", -1, "number of entries to dump (default: all)")
+	format   = flag.String("format", "table", "output format (table, json or go)")
+)
+
+func main() {
+	flag.Parse()
+
+	if *fname == "" && flag.NArg() > 0 {
+		*fname = flag.Arg(0)
+	}
+
+	if *fname == "" {
+		fmt.Fprintf(os.Stderr, "**error** you have to give a (valid) path to a ROOT file\n")
+		os.Exit(1)
+	}
+
+	if *first < 0 {
+		fmt.Fprintf(os.Stderr, "**error** invalid first entry [%d]\n", *first)
+		os.Exit(1)
+	}
+
+	var printer func(w io.Writer, cols []croot.Column) entryPrinter
+	switch *format {
+	case "table":
+		printer = newTablePrinter
+	case "json":
+		printer = newJSONPrinter
+	case "go":
+		printer = newGoPrinter
+	default:
+		fmt.Fprintf(os.Stderr, "**error** invalid output format [%s]\n", *format)
+		os.Exit(1)
+	}
+
+	f, err := croot.OpenFile(*fname, "read", "go-croot-dump-file", 1, 0)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "**error** %v\n", err)
+		os.Exit(1)
+	}
+	defer f.Close("")
+
+	if *tname == "" {
+		for _, k := range f.GetListOfKeys() {
+			if k.ClassName == "TTree" || k.ClassName == "TNtuple" || k.ClassName == "TNtupleD" {
+				*tname = k.Name
+				break
+			}
+		}
+	}
+	tree := f.GetTree(*tname)
+	if tree == nil {
+		fmt.Fprintf(os.Stderr, "**error** no such tree [%s]\n", *tname)
+		os.Exit(1)
+	}
+
+	var sel []string
+	for _, b := range strings.Split(*branches, ",") {
+		if b = strings.TrimSpace(b); b != "" {
+			sel = append(sel, b)
+		}
+	}
+
+	r, err := croot.NewReader(tree, sel...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "**error** %v\n", err)
+		os.Exit(1)
+	}
+	defer r.Close()
+
+	for _, name := range r.Skipped() {
+		fmt.Fprintf(os.Stderr, "**warning** skipping leaf [%s] (unsupported type)\n", name)
+	}
+
+	end := tree.GetEntries()
+	if *nentries >= 0 && *first+*nentries < end {
+		end = *first + *nentries
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	p := printer(out, r.Columns())
+	for i := *first; i < end; i++ {
+		vals, err := r.Read(i)
+		if err != nil {
+			out.Flush()
+			fmt.Fprintf(os.Stderr, "**error** %v\n", err)
+			os.Exit(1)
+		}
+		p.print(i, vals)
+	}
+	p.flush()
+	out.Flush()
+}
+
+type entryPrinter interface {
+	print(entry int64, vals []interface{})
+	flush()
+}
+
+// tablePrinter prints entries as the rows of a table
+type tablePrinter struct {
+	w *tabwriter.Writer
+}
+
+func newTablePrinter(w io.Writer, cols []croot.Column) entryPrinter {
+	p := &tablePrinter{tabwriter.NewWriter(w, 0, 8, 1, ' ', 0)}
+	fmt.Fprintf(p.w, "entry")
+	for _, col := range cols {
+		fmt.Fprintf(p.w, "\t| %s", col.Name)
+	}
+	fmt.Fprintf(p.w, "\t\n")
+	return p
+}
+
+func (p *tablePrinter) print(entry int64, vals []interface{}) {
+	fmt.Fprintf(p.w, "%d", entry)
+	for _, v := range vals {
+		fmt.Fprintf(p.w, "\t| %v", v)
+	}
+	fmt.Fprintf(p.w, "\t\n")
+}
+
+func (p *tablePrinter) flush() {
+	p.w.Flush()
+}
+
+// jsonPrinter prints entries as one JSON object per line, keeping the
+// order of the columns.
+type jsonPrinter struct {
+	w    io.Writer
+	keys []string
+}
+
+func newJSONPrinter(w io.Writer, cols []croot.Column) entryPrinter {
+	p := &jsonPrinter{w: w, keys: make([]string, len(cols))}
+	for i, col := range cols {
+		key, _ := json.Marshal(col.Name)
+		p.keys[i] = string(key)
+	}
+	return p
+}
+
+func (p *jsonPrinter) print(entry int64, vals []interface{}) {
+	fmt.Fprintf(p.w, `{"entry":%d`, entry)
+	for i, v := range vals {
+		fmt.Fprintf(p.w, ",%s:%s", p.keys[i], jsonValue(v))
+	}
+	fmt.Fprintf(p.w, "}\n")
+}
+
+func (p *jsonPrinter) flush() {}
+
+// jsonValue returns the JSON encoding of v. NaNs and infinities, which have
+// no JSON representation, are encoded as strings.
+func jsonValue(v interface{}) []byte {
+	o, err := json.Marshal(v)
+	if err == nil {
+		return o
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Float32, reflect.Float64:
+		o, _ = json.Marshal(fmt.Sprintf("%v", rv.Float()))
+		return o
+	case reflect.Array, reflect.Slice:
+		elems := make([]string, rv.Len())
+		for i := range elems {
+			elems[i] = string(jsonValue(rv.Index(i).Interface()))
+		}
+		return []byte("[" + strings.Join(elems, ",") + "]")
+	}
+	o, _ = json.Marshal(fmt.Sprintf("%v", v))
+	return o
+}
+
+// goPrinter prints entries as Go-syntax map[string]interface{} composite
+// literals, one per entry
+type goPrinter struct {
+	w     io.Writer
+	names []string
+}
+
+func newGoPrinter(w io.Writer, cols []croot.Column) entryPrinter {
+	p := &goPrinter{w: w, names: make([]string, len(cols))}
+	for i, col := range cols {
+		p.names[i] = col.Name
+	}
+	return p
+}
+
+func (p *goPrinter) print(entry int64, vals []interface{}) {
+	fmt.Fprintf(p.w, "// entry %d\nmap[string]interface{}{\n", entry)
+	for i, v := range vals {
+		fmt.Fprintf(p.w, "\t%q: %s,\n", p.names[i], goValue(v))
+	}
+	fmt.Fprintf(p.w, "}\n")
+}
+
+func (p *goPrinter) flush() {}
+
+// goValue returns the Go-syntax representation of v, including its type.
+func goValue(v interface{}) string {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Array, reflect.Slice:
+		if k := rv.Type().Elem().Kind(); k == reflect.Float32 || k == reflect.Float64 {
+			elems := make([]string, rv.Len())
+			for i := range elems {
+				elems[i] = goFloat(rv.Index(i))
+			}
+			return fmt.Sprintf("%s{%s}", rv.Type(), strings.Join(elems, ", "))
+		}
+		return fmt.Sprintf("%#v", v)
+	case reflect.String, reflect.Struct:
+		return fmt.Sprintf("%#v", v)
+	case reflect.Float32, reflect.Float64:
+		if f := rv.Float(); math.IsNaN(f) || math.IsInf(f, 0) {
+			return goFloat(rv)
+		}
+	}
+	return fmt.Sprintf("%s(%#v)", rv.Type(), v)
+}
+
+// goFloat returns the Go-syntax representation of the float rv, NaNs and
+// infinities being converted from the math functions.
+func goFloat(rv reflect.Value) string {
+	f := rv.Float()
+	switch {
+	case math.IsNaN(f):
+		return fmt.Sprintf("%s(math.NaN())", rv.Type())
+	case math.IsInf(f, 0):
+		return fmt.Sprintf("%s(math.Inf(%d))", rv.Type(), int(math.Copysign(1, f)))
+	}
+	return fmt.Sprintf("%#v", rv.Interface())
+}
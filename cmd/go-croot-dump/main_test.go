@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-hep/croot"
+)
+
+// entries to print, with arrays, slices, struct leaves and special floats
+var (
+	testCols = []croot.Column{
+		{Name: "n", Type: reflect.TypeOf(int32(0))},
+		{Name: "fix", Type: reflect.TypeOf([2]float64{})},
+		{Name: "v", Type: reflect.TypeOf([]float32{})},
+		{Name: "s.a", Type: reflect.TypeOf(int32(0))},
+		{Name: "x", Type: reflect.TypeOf(float64(0))},
+	}
+	testVals = [][]interface{}{
+		{int32(1), [2]float64{1.5, 2}, []float32{3}, int32(4), math.NaN()},
+		{int32(0), [2]float64{math.Inf(-1), 0}, []float32{}, int32(-1), math.Inf(1)},
+	}
+)
+
+func testPrint(newPrinter func(w io.Writer, cols []croot.Column) entryPrinter) string {
+	o := new(bytes.Buffer)
+	p := newPrinter(o, testCols)
+	for i, vals := range testVals {
+		p.print(int64(i), vals)
+	}
+	p.flush()
+	return o.String()
+}
+
+func TestTablePrinter(t *testing.T) {
+	want := [][]string{
+		{"entry", "n", "fix", "v", "s.a", "x"},
+		{"0", "1", "[1.5 2]", "[3]", "4", "NaN"},
+		{"1", "0", "[-Inf 0]", "[]", "-1", "+Inf"},
+	}
+	lines := strings.Split(strings.TrimSuffix(testPrint(newTablePrinter), "\n"), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("expected %d lines, got %d:\n%s", len(want), len(lines), strings.Join(lines, "\n"))
+	}
+	width := -1
+	for i, line := range lines {
+		cells := strings.Split(line, "|")
+		for j := range cells {
+			cells[j] = strings.TrimSpace(cells[j])
+		}
+		if !reflect.DeepEqual(cells, want[i]) {
+			t.Errorf("line %d: expected %q, got %q", i, want[i], cells)
+		}
+		// columns are aligned
+		if w := strings.LastIndex(line, "|"); width >= 0 && w != width {
+			t.Errorf("line %d: misaligned columns:\n%s", i, line)
+		} else {
+			width = w
+		}
+	}
+}
+
+func TestJSONPrinter(t *testing.T) {
+	want := `{"entry":0,"n":1,"fix":[1.5,2],"v":[3],"s.a":4,"x":"NaN"}
+{"entry":1,"n":0,"fix":["-Inf",0],"v":[],"s.a":-1,"x":"+Inf"}
+`
+	if got := testPrint(newJSONPrinter); got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestGoPrinter(t *testing.T) {
+	want := `// entry 0
+map[string]interface{}{
+	"n": int32(1),
+	"fix": [2]float64{1.5, 2},
+	"v": []float32{3},
+	"s.a": int32(4),
+	"x": float64(math.NaN()),
+}
+// entry 1
+map[string]interface{}{
+	"n": int32(0),
+	"fix": [2]float64{float64(math.Inf(-1)), 0},
+	"v": []float32{},
+	"s.a": int32(-1),
+	"x": float64(math.Inf(1)),
+}
+`
+	got := testPrint(newGoPrinter)
+	if got != want {
+		t.Errorf("expected:\n%s\ngot:\n%s", want, got)
+	}
+
+	// each entry is a valid Go expression
+	entries := strings.Split(got, "// entry ")[1:]
+	if len(entries) != len(testVals) {
+		t.Fatalf("expected %d entries, got %d", len(testVals), len(entries))
+	}
+	for i, entry := range entries {
+		src := entry[strings.Index(entry, "\n")+1:]
+		expr, err := parser.ParseExpr(src)
+		if err != nil {
+			t.Errorf("entry %d: invalid Go expression: %v\n%s", i, err, src)
+			continue
+		}
+		lit, ok := expr.(*ast.CompositeLit)
+		if !ok {
+			t.Errorf("entry %d: expected a composite literal, got %T", i, expr)
+			continue
+		}
+		if _, ok := lit.Type.(*ast.MapType); !ok {
+			t.Errorf("entry %d: expected a map literal, got %T", i, lit.Type)
+		}
+		if len(lit.Elts) != len(testCols) {
+			t.Errorf("entry %d: expected %d elements, got %d", i, len(testCols), len(lit.Elts))
+		}
+	}
+}
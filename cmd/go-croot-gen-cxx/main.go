@@ -0,0 +1,123 @@
+// go-croot-gen-cxx generates the C++ declarations of the Go types a package
+// registers with croot.RegisterType, so the ROOT files written from Go can
+// be read natively from C++:
+//
+//	$ go-croot-gen-cxx [options] import/path/of/package
+//	$ go-croot-gen-cxx -o dict -n event github.com/me/analysis/event
+//	$ cd dict && rootcling -f eventDict.cxx event.h eventLinkDef.h
+//	$ cd dict && genreflex event.h -s event_selection.xml
+//
+// the types are registered by importing the package (from a temporary
+// program, run with "go run" from the current directory) and declared by
+// croot.GenCxxDict:
+//   - name.h declares the types,
+//   - nameLinkDef.h is the LinkDef file for rootcling (or rootcint),
+//   - name_selection.xml is the selection file for genreflex.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"text/template"
+)
+
+var (
+	pkg   = flag.String("p", "", "import path of the Go package registering the types")
+	oname = flag.String("o", ".", "directory to fill with the C++ files")
+	name  = flag.String("n", "", "base name of the C++ files (default: the name of the package)")
+)
+
+func main() {
+	flag.Parse()
+
+	if *pkg == "" {
+		*pkg = flag.Arg(0)
+	}
+	if *pkg == "" {
+		fmt.Fprintf(os.Stderr, "**error** you have to give the import path of a Go package\n")
+		os.Exit(1)
+	}
+	if *name == "" {
+		*name = path.Base(*pkg)
+	}
+
+	err := run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "**error** %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	dir, err := filepath.Abs(*oname)
+	if err != nil {
+		return err
+	}
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+
+	// the program has to be built from the current directory to resolve
+	// the package like the user would.
+	tmp, err := ioutil.TempDir(".", "go-croot-gen-cxx-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmp)
+
+	src, err := os.Create(filepath.Join(tmp, "main.go"))
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	err = template.Must(template.New("prog").Parse(prog_tmpl)).Execute(src, struct {
+		Pkg, Dir, Name string
+	}{*pkg, dir, *name})
+	if err != nil {
+		return fmt.Errorf("could not generate program: %v", err)
+	}
+	err = src.Close()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command("go", "run", src.Name())
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	err = cmd.Run()
+	if err != nil {
+		return fmt.Errorf("could not declare the types of package [%s]: %v", *pkg, err)
+	}
+
+	fmt.Fprintf(os.Stderr, ":: generated [%s.h], [%sLinkDef.h] and [%s_selection.xml] in [%s]\n", *name, *name, *name, dir)
+	return nil
+}
+
+const prog_tmpl = `// automatically generated by go-croot-gen-cxx!
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-hep/croot"
+
+	_ {{printf "%q" .Pkg}}
+)
+
+func main() {
+	err := croot.GenCxxDict({{printf "%q" .Dir}}, {{printf "%q" .Name}})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "**error** %v\n", err)
+		os.Exit(1)
+	}
+}
+`
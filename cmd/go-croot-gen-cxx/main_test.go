@@ -0,0 +1,64 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenCxx(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-croot-gen-cxx-")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	*pkg = "github.com/go-hep/croot/cmd/go-croot-gen-cxx/testdata/event"
+	*oname = dir
+	*name = "event"
+	defer func() {
+		*pkg, *oname, *name = "", ".", ""
+	}()
+
+	err = run()
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for fname, want := range map[string][]string{
+		"event.h": {
+			"struct string {\n    int32_t Len;\n    char *Data; //[Len]\n  };",
+			"struct slice<float64> {\n    int32_t Len;\n    int32_t Cap;\n    double *Data; //[Len]\n  };",
+			"struct Hit {\n  double X;\n  double Y;\n};",
+			"struct Event {\n  int64_t I;\n  golang::string Name;\n  Hit Pos;\n  golang::slice<float64> E;\n};",
+		},
+		"eventLinkDef.h": {
+			"#pragma link C++ class golang::string+;",
+			"#pragma link C++ class golang::slice<float64>+;",
+			"#pragma link C++ class Hit+;\n#pragma link C++ class Event+;",
+		},
+		"event_selection.xml": {
+			`<class name="golang::string" />`,
+			`<class name="Hit" />`,
+			`<class name="Event" />`,
+		},
+	} {
+		buf, err := ioutil.ReadFile(filepath.Join(dir, fname))
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+		for _, w := range want {
+			if !strings.Contains(string(buf), w) {
+				t.Errorf("%s: expected %q in:\n%s", fname, w, buf)
+			}
+		}
+	}
+
+	// the temporary program is removed.
+	tmps, err := filepath.Glob("go-croot-gen-cxx-*")
+	if err != nil || len(tmps) != 0 {
+		t.Errorf("temporary files left behind: %v (err=%v)", tmps, err)
+	}
+}
@@ -0,0 +1,23 @@
+// Package event registers the types declared by the tests of
+// go-croot-gen-cxx.
+package event
+
+import (
+	"github.com/go-hep/croot"
+)
+
+type Hit struct {
+	X float64
+	Y float64
+}
+
+type Event struct {
+	I    int64
+	Name string
+	Pos  Hit
+	E    []float64
+}
+
+func init() {
+	croot.RegisterType(&Event{})
+}
@@ -1,44 +1,177 @@
+// go-croot-gen-datareader generates the Go types and code to read (and
+// write back) the entries of ROOT trees:
+//
+//	$ go-croot-gen-datareader [options] file.root tree
+//	$ go-croot-gen-datareader -f file.root -t tree -p event -o event.go
+//	$ go-croot-gen-datareader -f file.root -t tree -include "jet_*,n" -exclude "*_tmp"
+//	$ go-croot-gen-datareader -c trees.json
+//
+// a DataReader (and a DataWriter creating the same branches) is generated
+// for the tree.
+// several trees can be described in a JSON configuration file:
+//
+//	{
+//	  "package": "event",
+//	  "output":  "event_gen.go",
+//	  "trees": [
+//	    {"file": "data.root", "tree": "events", "name": "Event", "exclude": ["*_tmp"]},
+//	    {"file": "data.root", "tree": "runs",   "name": "Run",   "include": ["run*"], "readonly": true}
+//	  ]
+//	}
+//
+// "name" prefixes the generated types (EventReader, EventWriter, ...), and
+// defaults to "Data".
+// the include and exclude patterns (see path.Match) select the top-level
+// branches to generate, by name.
+//
+// a DataWriter can only create leaflist branches, std::vectors and
+// std::strings: trees with split objects, other objects or variable-size
+// arrays whose count branch is not selected are reported as an error.
+// exclude those branches, or generate the DataReader only with -readonly
+// (or "readonly": true in the configuration file).
+//
+// the generator can be run from go:generate, the package defaulting to
+// $GOPACKAGE:
+//
+//	//go:generate go-croot-gen-datareader -c trees.json
 package main
 
 import (
+	"bytes"
+	"encoding/json"
 	"flag"
 	"fmt"
+	"go/format"
 	"io"
+	"io/ioutil"
 	"os"
+	"path"
+	"reflect"
 	"strings"
 	"text/template"
+	"unicode"
 
 	"github.com/go-hep/croot"
 )
 
 var (
-	fname = flag.String("f", "", "ROOT file to inspect")
-	tname = flag.String("t", "", "ROOT tree to inspect")
-	oname = flag.String("o", "event.go", "path to file to fill with Go code")
-	pname = flag.String("p", "main", "name of the Go package to generate")
+	cname    = flag.String("c", "", "JSON configuration file describing the trees to generate")
+	fname    = flag.String("f", "", "ROOT file to inspect")
+	tname    = flag.String("t", "", "ROOT tree to inspect")
+	oname    = flag.String("o", "event.go", "path to file to fill with Go code")
+	pname    = flag.String("p", "", "name of the Go package to generate (default: $GOPACKAGE or main)")
+	include  = flag.String("include", "", "comma-separated list of patterns of the branches to generate (default: all)")
+	exclude  = flag.String("exclude", "", "comma-separated list of patterns of the branches not to generate")
+	readonly = flag.Bool("readonly", false, "generate the DataReaders only (no DataWriter)")
+	verbose  = flag.Bool("v", false, "print the inspected branches")
 )
 
+type Config struct {
+	Package string       `json:"package"` // name of the Go package to generate
+	Output  string       `json:"output"`  // path to the generated file
+	Trees   []TreeConfig `json:"trees"`
+}
+
+type TreeConfig struct {
+	File     string   `json:"file"`
+	Tree     string   `json:"tree"`
+	Name     string   `json:"name"`     // prefix of the generated types (default: "Data")
+	Include  []string `json:"include"`  // patterns of the branches to generate (default: all)
+	Exclude  []string `json:"exclude"`  // patterns of the branches not to generate
+	ReadOnly bool     `json:"readonly"` // generate the DataReader only
+}
+
 type StructDef struct {
-	Name   string
-	Fields []FieldDef
+	Name     string
+	Fields   []FieldDef
+	Register bool // bound as a whole to its branch: has to be registered with CRoot
 }
 
 type FieldDef struct {
 	Name       string
 	Type       string
-	VarName    string
+	VarName    string // path to the field from the DataReader
 	BranchName string
+	Leaflist   string // leaflist of the branch (empty for std::vectors and std::strings)
+	BufSize    int    // buffer size of the branch
+	Count      string // name of the count branch of variable-size arrays
+}
+
+// CountDef describes a count field, set from the length of its arrays when
+// filling the DataWriter
+type CountDef struct {
+	VarName string
+	Type    string
+	Len     string   // path to the (first) array field
+	Others  []string // paths to the other array fields, which must have the same length
+}
+
+// TreeDef describes the code generated for a tree
+type TreeDef struct {
+	Tree       string // name of the ROOT tree
+	Prefix     string // prefix of the generated types
+	Reader     string // name of the DataReader type
+	Writer     string // name of the DataWriter type (empty if it can not be generated)
+	Fields     []FieldDef
+	Bindings   []FieldDef // fields connected to a branch
+	Counts     []CountDef
+	Unwritable []string // branches a DataWriter can not create (see gen_tree)
 }
 
 type Context struct {
-	Package    string
-	DataReader *StructDef
-	Defs       map[string]*StructDef
+	Package string
+	Trees   []*TreeDef
+	Defs    map[string]*StructDef
+	types   idents // names of the generated types
 }
 
-var to_go_name = strings.Title
+// idents hands out unique Go identifiers
+type idents map[string]int
+
+// reserved are the names of the fields and methods of the DataReader and
+// DataWriter types, fields can not use them
+var reserved = []string{"Tree", "Init", "GetEntry", "Fill", "Close"}
+
+func new_idents(names ...string) idents {
+	ids := make(idents)
+	for _, n := range names {
+		ids[n] = 1
+	}
+	return ids
+}
+
+// unique returns name, suffixed with "_N" if it is already in use.
+func (ids idents) unique(name string) string {
+	id := name
+	for ids[id] > 0 {
+		id = fmt.Sprintf("%s_%d", name, ids[name])
+		ids[name]++
+	}
+	ids[id]++
+	return id
+}
 
-func gen_code(w io.Writer, ctx Context) error {
+// to_go_name returns an exported Go identifier for the ROOT name n
+// ("px" -> "Px", "jet.pt" -> "Jet_pt", "1st" -> "X1st").
+func to_go_name(n string) string {
+	n = strings.Map(func(r rune) rune {
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return '_'
+	}, strings.TrimSpace(n))
+	if n == "" {
+		return "X"
+	}
+	r := []rune(n)
+	if !unicode.IsLetter(r[0]) {
+		return "X" + n
+	}
+	r[0] = unicode.ToUpper(r[0])
+	return string(r)
+}
+
+func gen_code(w io.Writer, ctx *Context) error {
 	var err error
 	t := template.New("top")
 	template.Must(t.Parse(code_tmpl))
@@ -46,130 +179,334 @@ func gen_code(w io.Writer, ctx Context) error {
 	return err
 }
 
-func main() {
+// gen_tree describes the DataReader (and DataWriter) of a tree.
+func gen_tree(ctx *Context, cfg TreeConfig) error {
+	for _, pattern := range append(cfg.Include, cfg.Exclude...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid branch pattern [%s]: %v", pattern, err)
+		}
+	}
+	if cfg.Name == "" {
+		cfg.Name = "Data"
+	}
 
-	fmt.Printf(":: croot-reader ::\n")
-	flag.Parse()
+	td := &TreeDef{
+		Tree:   cfg.Tree,
+		Prefix: to_go_name(cfg.Name),
+	}
+	td.Reader = ctx.types.unique(td.Prefix + "Reader")
+	td.Writer = ctx.types.unique(td.Prefix + "Writer")
+	// constructors
+	ctx.types["New"+td.Reader]++
+	ctx.types["New"+td.Writer]++
 
-	if *fname == "" && len(os.Args) > 1 {
-		*fname = os.Args[1]
+	f, err := croot.OpenFile(cfg.File, "read", "go-croot-gen-datareader", 1, 0)
+	if err != nil {
+		return err
 	}
+	defer f.Close("")
 
-	if *fname == "" {
-		fmt.Printf("**error** you have to give a (valid) path to a ROOT file\n")
-		os.Exit(1)
+	tree := f.GetTree(cfg.Tree)
+	if tree == nil {
+		return fmt.Errorf("no such tree [%s] in file [%s]", cfg.Tree, cfg.File)
+	}
+	if *verbose {
+		fmt.Printf(":: file: %s\n", f.GetName())
+		fmt.Printf(":: tree: %s (entries=%v)\n", cfg.Tree, tree.GetEntries())
 	}
 
-	if *tname == "" && len(os.Args) > 2 {
-		*tname = os.Args[2]
+	names := new_idents(reserved...)
+	for i, branch := range tree.GetListOfBranches() {
+		n := strings.TrimSuffix(branch.GetName(), ".")
+		if !selected(n, cfg.Include, cfg.Exclude) {
+			continue
+		}
+		go_name := names.unique(to_go_name(n))
+		if *verbose {
+			fmt.Printf(":: branch[%3d]=%s (=> %s)\n", i, n, go_name)
+		}
+		field, err := gen_branch(ctx, td, branch, go_name, go_name)
+		if err != nil {
+			return err
+		}
+		td.Fields = append(td.Fields, field)
+	}
+	if len(td.Fields) == 0 {
+		return fmt.Errorf("no branch selected in tree [%s]", cfg.Tree)
 	}
 
-	if *tname == "" {
-		fmt.Printf("**error** you have to give a TTree name to inspect off the ROOT file\n")
-		os.Exit(1)
+	gen_counts(td)
+	switch {
+	case cfg.ReadOnly:
+		td.Writer = ""
+	case len(td.Unwritable) > 0:
+		return fmt.Errorf(
+			"no %s for tree [%s]: branches %v can not be created back from Go values (exclude them, or use -readonly)",
+			td.Writer, cfg.Tree, td.Unwritable,
+		)
 	}
 
-	if *oname == "" {
-		fmt.Printf("**error** you have to give a path to an output file (which will hold the generated Go code.)\n")
-		os.Exit(1)
+	ctx.Trees = append(ctx.Trees, td)
+	return nil
+}
+
+// selected returns whether the branch name matches one of the include
+// patterns (if any) and none of the exclude patterns.
+func selected(name string, include, exclude []string) bool {
+	ok := len(include) == 0
+	for _, pattern := range include {
+		if m, _ := path.Match(pattern, name); m {
+			ok = true
+			break
+		}
 	}
+	for _, pattern := range exclude {
+		if m, _ := path.Match(pattern, name); m {
+			return false
+		}
+	}
+	return ok
+}
 
-	if *pname == "" {
-		fmt.Printf("**error** you have to give a (Go) package name to generate\n")
-		os.Exit(1)
+// gen_branch describes the Go field holding the content of branch, stored
+// at path in the DataReader.
+func gen_branch(ctx *Context, td *TreeDef, branch croot.Branch, name, path string) (FieldDef, error) {
+	bname := branch.GetName()
+	field := FieldDef{Name: name, VarName: path, BranchName: bname}
+
+	// split objects: a struct with a field per sub-branch.
+	if subs := branch.GetListOfBranches(); len(subs) > 0 {
+		def := &StructDef{Name: struct_name(ctx, td, path)}
+		names := new_idents()
+		for _, sub := range subs {
+			sname := sub.GetName()
+			if i := strings.LastIndex(sname, "."); i >= 0 {
+				sname = sname[i+1:]
+			}
+			if i := strings.Index(sname, "["); i >= 0 {
+				sname = sname[:i]
+			}
+			sname = names.unique(to_go_name(sname))
+			f, err := gen_branch(ctx, td, sub, sname, path+"."+sname)
+			if err != nil {
+				return field, err
+			}
+			def.Fields = append(def.Fields, f)
+		}
+		ctx.Defs[def.Name] = def
+		field.Type = def.Name
+		td.Unwritable = append(td.Unwritable, bname)
+		return field, nil
 	}
 
-	o, err := os.Create(*oname)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "**error** %v\n", err)
-		os.Exit(1)
+	leaves := branch.GetListOfLeaves()
+	switch len(leaves) {
+	case 0:
+		return field, fmt.Errorf("branch [%s] has no leaf", bname)
+
+	case 1:
+		typ, err := croot.LeafType(leaves[0])
+		if err != nil {
+			return field, fmt.Errorf("branch [%s] (class [%s]): %v", bname, branch.GetClassName(), err)
+		}
+		field.Type = typ.String()
+		field.BufSize = branch.GetBasketSize()
+		switch cls := branch.GetClassName(); {
+		case cls == "":
+			field.Leaflist = branch.GetTitle()
+			if count := leaves[0].GetLeafCount(); count != nil {
+				field.Count = count.GetBranch().GetName()
+			}
+		case typ.Kind() == reflect.Slice, typ.Kind() == reflect.String:
+			// std::vector or std::string
+		default:
+			td.Unwritable = append(td.Unwritable, bname)
+		}
+		td.Bindings = append(td.Bindings, field)
+		return field, nil
 	}
-	defer func(o *os.File) {
-		o.Sync()
-		o.Close()
-	}(o)
 
-	ctx := Context{
-		Package: *pname,
-		Defs: map[string]*StructDef{
-			"DataReader": &StructDef{
-				Name:   "DataReader",
-				Fields: nil,
-			},
-		},
+	// leaflists: a struct with a field per leaf, bound as a whole.
+	def := &StructDef{Name: struct_name(ctx, td, path), Register: true}
+	names := new_idents()
+	for _, leaf := range leaves {
+		typ, err := croot.LeafType(leaf)
+		if err != nil {
+			return field, fmt.Errorf("branch [%s]: %v", bname, err)
+		}
+		switch typ.Kind() {
+		case reflect.Slice, reflect.String:
+			return field, fmt.Errorf(
+				"branch [%s]: leaf [%s] of type [%v] is not supported in a multi-leaves branch",
+				bname, leaf.GetName(), typ,
+			)
+		}
+		def.Fields = append(def.Fields, FieldDef{
+			Name:       names.unique(to_go_name(leaf.GetName())),
+			Type:       typ.String(),
+			BranchName: leaf.GetName(),
+		})
 	}
+	ctx.Defs[def.Name] = def
+	field.Type = def.Name
+	field.Leaflist = branch.GetTitle()
+	field.BufSize = branch.GetBasketSize()
+	td.Bindings = append(td.Bindings, field)
+	return field, nil
+}
 
-	f, err := croot.OpenFile(*fname, "read", "go-croot-gen-datareader", 1, 0)
-	if err != nil {
-		fmt.Printf("**error** %v\n", err)
-		os.Exit(1)
+// gen_counts associates the variable-size arrays with their count field, so
+// the DataWriter sets them from the length of the arrays (and checks the
+// arrays sharing a count have the same length).
+func gen_counts(td *TreeDef) {
+	fields := make(map[string]FieldDef, len(td.Bindings))
+	for _, f := range td.Bindings {
+		fields[f.BranchName] = f
+	}
+	counts := make(map[string]int) // index of the CountDef of a count branch
+	for _, f := range td.Bindings {
+		if f.Count == "" {
+			continue
+		}
+		if i, ok := counts[f.Count]; ok {
+			td.Counts[i].Others = append(td.Counts[i].Others, f.VarName)
+			continue
+		}
+		count, ok := fields[f.Count]
+		if !ok || count.Leaflist == "" || strings.Contains(count.Leaflist, ":") {
+			// count leaf of a leaflist, of an object or not selected.
+			td.Unwritable = append(td.Unwritable, f.BranchName)
+			continue
+		}
+		counts[f.Count] = len(td.Counts)
+		td.Counts = append(td.Counts, CountDef{
+			VarName: count.VarName,
+			Type:    count.Type,
+			Len:     f.VarName,
+		})
 	}
+}
 
-	defer f.Close("")
+// struct_name returns the (unique) name of the struct type of the field at
+// path ("Evt.P" -> "EvtP"), prefixed with the name of the tree.
+func struct_name(ctx *Context, td *TreeDef, path string) string {
+	name := strings.Replace(path, ".", "", -1)
+	if td.Prefix != "Data" {
+		name = td.Prefix + name
+	}
+	return ctx.types.unique(name)
+}
 
-	fmt.Printf(":: file: %s\n", f.GetName())
-	tree := f.GetTree(*tname)
-	if tree == nil {
-		fmt.Printf("**error** no such tree [%s]\n", *tname)
-		os.Exit(1)
+// split_list splits a comma-separated list of patterns.
+func split_list(s string) []string {
+	var list []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			list = append(list, v)
+		}
 	}
+	return list
+}
 
-	defs := ctx.Defs
-
-	fmt.Printf(":: tree: %s (entries=%v)\n", *tname, tree.GetEntries())
-	branches := tree.GetListOfBranches()
-	fmt.Printf(":: branches: %v\n", len(branches))
-	for i, branch := range branches {
-		n := branch.GetName()
-		go_name := to_go_name(n)
-		fmt.Printf(":: branch[%3d]=%s (=> %s)\n", i, n, go_name)
-		leaves := branch.GetListOfLeaves()
-		fmt.Printf(":: leaves: %v\n", len(leaves))
-		br_struct := StructDef{Name: go_name, Fields: nil}
-		for j, leaf := range leaves {
-			fmt.Printf("  [%03d] leaf: %v\n", j, n)
-			//leaf.Print("")
-			nn := to_go_name(leaf.GetName())
-			typename := leaf.GetTypeName()
-			gotype, ok := go_typemap[typename]
-			if !ok {
-				gotype = "Undefined"
-			}
-			br_field := FieldDef{
-				Name:       nn,
-				BranchName: leaf.GetName(),
-				VarName:    nn,
-				Type:       gotype,
-			}
-			if j == 0 {
-				br_field.VarName = n + "." + nn
-			}
-			br_struct.Fields = append(br_struct.Fields, br_field)
-
-		}
-		if len(br_struct.Fields) > 1 {
-			defs[n] = &br_struct
-			defs["DataReader"].Fields = append(
-				defs["DataReader"].Fields,
-				FieldDef{
-					Name:       go_name,
-					BranchName: n,
-					VarName:    br_struct.Fields[0].VarName,
-					Type:       go_name,
-				},
-			)
-		} else {
-			// lump into DataReader.
-			defs["DataReader"].Fields = append(
-				defs["DataReader"].Fields,
-				br_struct.Fields...,
-			)
+// load_config returns the configuration given with -c, or described by the
+// command line flags and arguments.
+func load_config() (Config, error) {
+	var cfg Config
+	if *cname != "" {
+		buf, err := ioutil.ReadFile(*cname)
+		if err != nil {
+			return cfg, err
+		}
+		err = json.Unmarshal(buf, &cfg)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid configuration file [%s]: %v", *cname, err)
+		}
+		if len(cfg.Trees) == 0 {
+			return cfg, fmt.Errorf("no tree described in configuration file [%s]", *cname)
+		}
+	} else {
+		if *fname == "" {
+			*fname = flag.Arg(0)
+		}
+		if *tname == "" {
+			*tname = flag.Arg(1)
+		}
+		cfg.Trees = []TreeConfig{{
+			File:    *fname,
+			Tree:    *tname,
+			Include: split_list(*include),
+			Exclude: split_list(*exclude),
+		}}
+	}
+	if *readonly {
+		for i := range cfg.Trees {
+			cfg.Trees[i].ReadOnly = true
 		}
 	}
 
-	ctx.DataReader = defs["DataReader"]
-	delete(defs, "DataReader")
+	if cfg.Package == "" {
+		cfg.Package = *pname
+	}
+	if cfg.Package == "" {
+		cfg.Package = os.Getenv("GOPACKAGE")
+	}
+	if cfg.Package == "" {
+		cfg.Package = "main"
+	}
+	if cfg.Output == "" {
+		cfg.Output = *oname
+	}
+
+	for _, tree := range cfg.Trees {
+		if tree.File == "" {
+			return cfg, fmt.Errorf("you have to give a (valid) path to a ROOT file")
+		}
+		if tree.Tree == "" {
+			return cfg, fmt.Errorf("you have to give a TTree name to inspect off the ROOT file [%s]", tree.File)
+		}
+	}
+	if cfg.Output == "" {
+		return cfg, fmt.Errorf("you have to give a path to an output file (which will hold the generated Go code.)")
+	}
+	return cfg, nil
+}
+
+func run() error {
+	cfg, err := load_config()
+	if err != nil {
+		return err
+	}
+
+	ctx := &Context{
+		Package: cfg.Package,
+		Defs:    make(map[string]*StructDef),
+		types:   new_idents(),
+	}
+	for _, tree := range cfg.Trees {
+		err = gen_tree(ctx, tree)
+		if err != nil {
+			return err
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	err = gen_code(buf, ctx)
+	if err != nil {
+		return fmt.Errorf("could not generate code: %v", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return fmt.Errorf("generated invalid code: %v", err)
+	}
+	return ioutil.WriteFile(cfg.Output, src, 0644)
+}
 
-	err = gen_code(o, ctx)
-	os.Exit(0)
+func main() {
+	flag.Parse()
+
+	err := run()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "**error** %v\n", err)
+		os.Exit(1)
+	}
 }
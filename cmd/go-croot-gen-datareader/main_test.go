@@ -0,0 +1,540 @@
+package main
+
+import (
+	"bytes"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-hep/croot"
+)
+
+// createTree writes a tree with scalars, fixed and variable-size arrays
+// (sharing their count), a leaflist struct and a std::vector.
+func createTree(t *testing.T, fname string) {
+	f, err := croot.OpenFile(fname, "recreate", "go-croot-gen-datareader test", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+
+	tree := croot.NewTree("tree", "tree", 32)
+	var evt struct {
+		N   int32
+		V   []float32
+		W   []float64
+		Fix [3]float64
+		S   struct {
+			A int32
+			B float64
+		}
+		Vec []float64
+	}
+	for _, br := range []struct {
+		name     string
+		addr     interface{}
+		leaflist string
+	}{
+		{"n", &evt.N, "n/I"},
+		{"v", &evt.V, "v[n]/F"},
+		{"w", &evt.W, "w[n]/D"},
+		{"fix", &evt.Fix, "fix[3]/D"},
+		{"s", &evt.S, "a/I:b/D"},
+	} {
+		_, err = tree.Branch2(br.name, br.addr, br.leaflist, 32000)
+		if err != nil {
+			t.Fatalf("branch [%s]: %v", br.name, err)
+		}
+	}
+	_, err = tree.Branch("vec", &evt.Vec, 32000, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	for i := 0; i != 3; i++ {
+		evt.V = append(evt.V, float32(i))
+		evt.W = append(evt.W, float64(i))
+		evt.N = int32(len(evt.V))
+		_, err = tree.Fill()
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+	f.Write("", 0, 0)
+}
+
+// struct_fields returns the types of the fields of the struct type name
+// declared in f.
+func struct_fields(f *ast.File, name string) map[string]string {
+	fields := make(map[string]string)
+	ast.Inspect(f, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok || spec.Name.Name != name {
+			return true
+		}
+		for _, field := range spec.Type.(*ast.StructType).Fields.List {
+			for _, id := range field.Names {
+				fields[id.Name] = types.ExprString(field.Type)
+			}
+		}
+		return false
+	})
+	return fields
+}
+
+func TestGenCode(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-croot-gen-datareader-")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+	fname := filepath.Join(dir, "tree.root")
+	createTree(t, fname)
+
+	ctx := &Context{
+		Package: "event",
+		Defs:    make(map[string]*StructDef),
+		types:   new_idents(),
+	}
+	err = gen_tree(ctx, TreeConfig{File: fname, Tree: "tree", Name: "Event"})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	err = gen_tree(ctx, TreeConfig{File: fname, Tree: "tree", Name: "Event", Include: []string{"n", "v*"}})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(ctx.Trees) != 2 || ctx.Trees[0].Writer != "EventWriter" || ctx.Trees[1].Reader != "EventReader_1" {
+		t.Fatalf("unexpected trees: %+v", ctx.Trees)
+	}
+
+	buf := new(bytes.Buffer)
+	err = gen_code(buf, ctx)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		t.Fatalf("generated invalid code: %v\n%s", err, buf.String())
+	}
+
+	f, err := parser.ParseFile(token.NewFileSet(), "event.go", src, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if f.Name.Name != "event" {
+		t.Errorf("expected package [event], got [%s]", f.Name.Name)
+	}
+
+	for _, test := range []struct {
+		name   string
+		fields map[string]string
+	}{
+		{
+			"EventWriter",
+			map[string]string{
+				"N":    "int32",
+				"V":    "[]float32",
+				"W":    "[]float64",
+				"Fix":  "[3]float64",
+				"S":    "EventS",
+				"Vec":  "[]float64",
+				"Tree": "croot.Tree",
+			},
+		},
+		{
+			"EventS",
+			map[string]string{"A": "int32", "B": "float64"},
+		},
+		{
+			"EventWriter_1",
+			map[string]string{
+				"N":    "int32",
+				"V":    "[]float32",
+				"Vec":  "[]float64",
+				"Tree": "croot.Tree",
+			},
+		},
+	} {
+		if got := struct_fields(f, test.name); !reflect.DeepEqual(got, test.fields) {
+			t.Errorf("type %s: expected fields %v, got %v", test.name, test.fields, got)
+		}
+	}
+
+	for _, want := range []string{
+		"func NewEventReader(tree croot.Tree) (*EventReader, error)",
+		"func NewEventWriter(tree croot.Tree) (*EventWriter, error)",
+		`dr.Tree.SetBranchAddress("fix", &dr.Fix)`,
+		`dw.Tree.Branch2("s", &dw.S, "a/I:b/D", `,
+		`dw.Tree.Branch("vec", &dw.Vec, `,
+		"dw.N = int32(len(dw.V))",
+		"if len(dw.W) != len(dw.V) {",
+		"croot.RegisterType(&EventS{})",
+	} {
+		if !bytes.Contains(src, []byte(want)) {
+			t.Errorf("missing [%s] in generated code:\n%s", want, src)
+		}
+	}
+}
+
+func TestGenUnwritable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-croot-gen-datareader-")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+	fname := filepath.Join(dir, "tree.root")
+	createTree(t, fname)
+
+	// the count of v is not selected: no DataWriter.
+	cfg := TreeConfig{File: fname, Tree: "tree", Include: []string{"v"}}
+	ctx := &Context{Defs: make(map[string]*StructDef), types: new_idents()}
+	err = gen_tree(ctx, cfg)
+	if err == nil || !strings.Contains(err.Error(), "can not be created back") {
+		t.Fatalf("expected an error for the unwritable branch [v], got %v", err)
+	}
+
+	cfg.ReadOnly = true
+	ctx = &Context{Defs: make(map[string]*StructDef), types: new_idents()}
+	err = gen_tree(ctx, cfg)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if len(ctx.Trees) != 1 || ctx.Trees[0].Reader != "DataReader" || ctx.Trees[0].Writer != "" {
+		t.Fatalf("unexpected trees: %+v", ctx.Trees)
+	}
+}
+
+// roundtrip_main writes entries with the generated EventWriter and reads
+// them back with the generated EventReader.
+const roundtrip_main = `package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-hep/croot"
+)
+
+const evtmax = 5
+
+// event returns the values of the entry i.
+func event(i int) EventWriter {
+	var evt EventWriter
+	for j := 0; j < i; j++ {
+		evt.V = append(evt.V, float32(i+j))
+		evt.W = append(evt.W, float64(-i-j))
+	}
+	evt.N = int32(len(evt.V))
+	evt.Fix = [3]float64{float64(i), float64(2 * i), float64(3 * i)}
+	evt.S = EventS{A: int32(i), B: float64(i) + 0.5}
+	for j := 0; j < i%3; j++ {
+		evt.Vec = append(evt.Vec, float64(10*i+j))
+	}
+	return evt
+}
+
+func write(fname string) error {
+	f, err := croot.OpenFile(fname, "recreate", "roundtrip", 1, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close("")
+
+	dw, err := NewEventWriter(croot.NewTree("tree", "tree", 32))
+	if err != nil {
+		return err
+	}
+	for i := 0; i != evtmax; i++ {
+		evt := event(i)
+		dw.V, dw.W, dw.Fix, dw.S, dw.Vec = evt.V, evt.W, evt.Fix, evt.S, evt.Vec
+		_, err = dw.Fill()
+		if err != nil {
+			return err
+		}
+	}
+	dw.W = append(dw.W, 1)
+	if _, err = dw.Fill(); err == nil {
+		return fmt.Errorf("expected an error filling v and w with different lengths")
+	}
+	return dw.Close()
+}
+
+func read(fname string) error {
+	f, err := croot.OpenFile(fname, "read", "roundtrip", 1, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close("")
+
+	dr, err := NewEventReader(f.GetTree("tree"))
+	if err != nil {
+		return err
+	}
+	if n := dr.Tree.GetEntries(); n != evtmax {
+		return fmt.Errorf("expected %d entries, got %d", evtmax, n)
+	}
+	for i := 0; i != evtmax; i++ {
+		if dr.GetEntry(int64(i)) <= 0 {
+			return fmt.Errorf("could not read entry %d", i)
+		}
+		got := EventWriter{N: dr.N, V: dr.V, W: dr.W, Fix: dr.Fix, S: dr.S, Vec: dr.Vec}
+		if want := event(i); fmt.Sprint(got) != fmt.Sprint(want) {
+			return fmt.Errorf("entry %d: expected %v, got %v", i, want, got)
+		}
+	}
+	return nil
+}
+
+func main() {
+	const fname = "roundtrip.root"
+	defer os.Remove(fname)
+	for _, step := range []func(string) error{write, read} {
+		if err := step(fname); err != nil {
+			fmt.Fprintf(os.Stderr, "**error** %v\n", err)
+			os.Exit(1)
+		}
+	}
+}
+`
+
+func TestGenRoundTrip(t *testing.T) {
+	// the generated code is built from a directory of this package, so
+	// it imports croot like the tests do ("_" dirs are ignored by ./...).
+	dir, err := ioutil.TempDir(".", "_roundtrip-")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+	fname := filepath.Join(dir, "tree.root")
+	createTree(t, fname)
+
+	ctx := &Context{
+		Package: "main",
+		Defs:    make(map[string]*StructDef),
+		types:   new_idents(),
+	}
+	err = gen_tree(ctx, TreeConfig{File: fname, Tree: "tree", Name: "Event"})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	buf := new(bytes.Buffer)
+	err = gen_code(buf, ctx)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		t.Fatalf("generated invalid code: %v\n%s", err, buf.String())
+	}
+
+	for name, src := range map[string][]byte{
+		"event.go": src,
+		"main.go":  []byte(roundtrip_main),
+	} {
+		err = ioutil.WriteFile(filepath.Join(dir, name), src, 0644)
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+
+	cmd := exec.Command("go", "run", "event.go", "main.go")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("round-trip failed: %v\n%s\ngenerated code:\n%s", err, out, src)
+	}
+}
+
+func TestGoNames(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		want string
+	}{
+		{"px", "Px"},
+		{"Px", "Px"},
+		{"jet.pt", "Jet_pt"},
+		{"a.b", "A_b"},
+		{"a_b", "A_b"},
+		{"1st", "X1st"},
+		{"_x", "X_x"},
+		{" x ", "X"},
+		{"", "X"},
+		{"p[3]", "P_3_"},
+		{"été", "Été"},
+	} {
+		if got := to_go_name(test.name); got != test.want {
+			t.Errorf("to_go_name(%q): expected %q, got %q", test.name, test.want, got)
+		}
+	}
+
+	// the names of the fields of a DataReader, as generated by gen_tree.
+	names := new_idents(reserved...)
+	for _, test := range []struct {
+		name string
+		want string
+	}{
+		{"a.b", "A_b"},
+		{"a_b", "A_b_1"},
+		{"A_b_1", "A_b_1_1"},
+		{"1st", "X1st"},
+		{"x", "X"},
+		{"x_1", "X_1"},
+		{"X", "X_2"},
+		{"tree", "Tree_1"},
+		{"Tree", "Tree_2"},
+		{"fill", "Fill_1"},
+		{"getEntry", "GetEntry_1"},
+		{"close", "Close_1"},
+		{"init", "Init_1"},
+	} {
+		if got := names.unique(to_go_name(test.name)); got != test.want {
+			t.Errorf("unique(%q): expected %q, got %q", test.name, test.want, got)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-croot-gen-datareader-")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer os.RemoveAll(dir)
+
+	// restore the flags and environment afterwards.
+	flags := []*string{cname, fname, tname, oname, pname, include, exclude}
+	saved := make([]string, len(flags))
+	for i, f := range flags {
+		saved[i] = *f
+	}
+	savedRO := *readonly
+	gopkg, hasGopkg := os.LookupEnv("GOPACKAGE")
+	defer func() {
+		for i, f := range flags {
+			*f = saved[i]
+		}
+		*readonly = savedRO
+		if hasGopkg {
+			os.Setenv("GOPACKAGE", gopkg)
+		} else {
+			os.Unsetenv("GOPACKAGE")
+		}
+	}()
+
+	for _, test := range []struct {
+		name     string
+		json     string // content of the configuration file (none if empty)
+		fname    string
+		tname    string
+		include  string
+		pname    string
+		readonly bool
+		gopkg    string
+		want     Config
+		err      string
+	}{
+		{
+			name:  "config, $GOPACKAGE",
+			json:  `{"trees": [{"file": "f.root", "tree": "t", "name": "Evt", "exclude": ["*_tmp"]}]}`,
+			gopkg: "event",
+			want: Config{
+				Package: "event",
+				Output:  "event.go",
+				Trees:   []TreeConfig{{File: "f.root", Tree: "t", Name: "Evt", Exclude: []string{"*_tmp"}}},
+			},
+		},
+		{
+			name:     "config, package and output",
+			json:     `{"package": "data", "output": "data_gen.go", "trees": [{"file": "f.root", "tree": "t"}, {"file": "g.root", "tree": "u", "readonly": true}]}`,
+			gopkg:    "event",
+			readonly: true,
+			want: Config{
+				Package: "data",
+				Output:  "data_gen.go",
+				Trees: []TreeConfig{
+					{File: "f.root", Tree: "t", ReadOnly: true},
+					{File: "g.root", Tree: "u", ReadOnly: true},
+				},
+			},
+		},
+		{
+			name:  "config, -p",
+			json:  `{"trees": [{"file": "f.root", "tree": "t"}]}`,
+			pname: "flagged",
+			gopkg: "event",
+			want: Config{
+				Package: "flagged",
+				Output:  "event.go",
+				Trees:   []TreeConfig{{File: "f.root", Tree: "t"}},
+			},
+		},
+		{
+			name:    "flags",
+			fname:   "f.root",
+			tname:   "t",
+			include: " n, v* ,",
+			want: Config{
+				Package: "main",
+				Output:  "event.go",
+				Trees:   []TreeConfig{{File: "f.root", Tree: "t", Include: []string{"n", "v*"}}},
+			},
+		},
+		{
+			name: "invalid JSON",
+			json: `{"trees": [`,
+			err:  "invalid configuration file",
+		},
+		{
+			name: "no tree",
+			json: `{"package": "event"}`,
+			err:  "no tree described",
+		},
+		{
+			name: "no file",
+			json: `{"trees": [{"tree": "t"}]}`,
+			err:  "path to a ROOT file",
+		},
+		{
+			name:  "no tree name",
+			fname: "f.root",
+			err:   "TTree name",
+		},
+	} {
+		*cname, *fname, *tname, *oname = "", test.fname, test.tname, "event.go"
+		*pname, *include, *exclude = test.pname, test.include, ""
+		*readonly = test.readonly
+		if test.json != "" {
+			*cname = filepath.Join(dir, "config.json")
+			err = ioutil.WriteFile(*cname, []byte(test.json), 0644)
+			if err != nil {
+				t.Fatalf(err.Error())
+			}
+		}
+		if test.gopkg != "" {
+			os.Setenv("GOPACKAGE", test.gopkg)
+		} else {
+			os.Unsetenv("GOPACKAGE")
+		}
+
+		cfg, err := load_config()
+		switch {
+		case test.err != "":
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%s: expected an error containing %q, got %v", test.name, test.err, err)
+			}
+		case err != nil:
+			t.Errorf("%s: %v", test.name, err)
+		case !reflect.DeepEqual(cfg, test.want):
+			t.Errorf("%s: expected %+v, got %+v", test.name, test.want, cfg)
+		}
+	}
+}
@@ -1,35 +1,40 @@
 package main
 
-const code_tmpl = `// automatically generated!
+// code_tmpl is the template of the generated code, which is run through
+// go/format afterwards.
+const code_tmpl = `// Code generated by go-croot-gen-datareader. DO NOT EDIT.
 
 package {{.Package}}
 
 import (
-  "fmt"
+	"fmt"
 
-  "github.com/go-hep/croot"
+	"github.com/go-hep/croot"
 )
-
 {{range .Defs}}
 type {{.Name}} struct {
 {{range .Fields}}	{{.Name}} {{.Type}}
 {{end}}}
 {{end}}
+{{range .Trees}}{{template "reader" .}}{{if .Writer}}{{template "writer" .}}{{end}}{{end}}
+func init() {
+	// register all generated types with CRoot
+{{range .Defs}}{{if .Register}}	croot.RegisterType(&{{.Name}}{})
+{{end}}{{end}}}
 
-{{with .DataReader}}
-type DataReader struct {
+{{define "reader"}}
+// {{.Reader}} reads the entries of the [{{.Tree}}] tree.
+type {{.Reader}} struct {
 {{range .Fields}}	{{.Name}} {{.Type}}
 {{end}}
-
- // branches
+	// branches
 {{range .Fields}}	b_{{.Name}} croot.Branch
 {{end}}
-
 	Tree croot.Tree
 }
 
-func NewDataReader(tree croot.Tree) (*DataReader, error) {
-	dr := &DataReader{}
+func New{{.Reader}}(tree croot.Tree) (*{{.Reader}}, error) {
+	dr := &{{.Reader}}{}
 	err := dr.Init(tree)
 	if err != nil {
 		return nil, err
@@ -37,12 +42,12 @@ func NewDataReader(tree croot.Tree) (*DataReader, error) {
 	return dr, nil
 }
 
-func (dr *DataReader) Init(tree croot.Tree) error {
+func (dr *{{.Reader}}) Init(tree croot.Tree) error {
 	var err error
 	var o int32
 	dr.Tree = tree
-{{range .Fields}}
-	o = dr.Tree.SetBranchAddress("{{.BranchName}}", &dr.{{.Name}})
+{{range .Bindings}}
+	o = dr.Tree.SetBranchAddress("{{.BranchName}}", &dr.{{.VarName}})
 	if o < 0 {
 		return fmt.Errorf("invalid branch: [{{.BranchName}}] (got %d)", o)
 	}
@@ -50,7 +55,7 @@ func (dr *DataReader) Init(tree croot.Tree) error {
 	return err
 }
 
-func (dr *DataReader) GetEntry(entry int64) int {
+func (dr *{{.Reader}}) GetEntry(entry int64) int {
 	if dr.Tree == nil {
 		return 0
 	}
@@ -58,12 +63,56 @@ func (dr *DataReader) GetEntry(entry int64) int {
 }
 {{end}}
 
+{{define "writer"}}
+// {{.Writer}} fills a tree with the branches of the [{{.Tree}}] tree.
+type {{.Writer}} struct {
+{{range .Fields}}	{{.Name}} {{.Type}}
+{{end}}
+	Tree croot.Tree
+}
 
-func init() {
-	// register all generated types with CRoot
-{{range .Defs}}	croot.RegisterType(&{{.Name}}{})
+// New{{.Writer}} creates on tree the branches of the {{.Reader}}.
+func New{{.Writer}}(tree croot.Tree) (*{{.Writer}}, error) {
+	dw := &{{.Writer}}{}
+	err := dw.Init(tree)
+	if err != nil {
+		return nil, err
+	}
+	return dw, nil
+}
+
+func (dw *{{.Writer}}) Init(tree croot.Tree) error {
+	var err error
+	dw.Tree = tree
+{{range .Bindings}}{{if .Leaflist}}
+	_, err = dw.Tree.Branch2("{{.BranchName}}", &dw.{{.VarName}}, {{printf "%q" .Leaflist}}, {{.BufSize}}){{else}}
+	_, err = dw.Tree.Branch("{{.BranchName}}", &dw.{{.VarName}}, {{.BufSize}}, 0){{end}}
+	if err != nil {
+		return fmt.Errorf("could not create branch [{{.BranchName}}]: %v", err)
+	}
 {{end}}
+	return err
 }
+
+// Fill fills the tree with the current values of the {{.Writer}}, setting
+// the counts of the variable-size arrays from their length.
+// The arrays sharing a count must have the same length.
+func (dw *{{.Writer}}) Fill() (int, error) {
+{{range .Counts}}{{$count := .}}{{range .Others}}	if len(dw.{{.}}) != len(dw.{{$count.Len}}) {
+		return 0, fmt.Errorf("arrays [{{$count.Len}}] and [{{.}}] of count [{{$count.VarName}}] differ in length (%d != %d)", len(dw.{{$count.Len}}), len(dw.{{.}}))
+	}
+{{end}}	dw.{{.VarName}} = {{.Type}}(len(dw.{{.Len}}))
+{{end}}	return dw.Tree.Fill()
+}
+
+// Close writes the tree into its file.
+func (dw *{{.Writer}}) Close() error {
+	if dw.Tree.Write("", 0, 0) <= 0 {
+		return fmt.Errorf("could not write tree [%s]", dw.Tree.GetName())
+	}
+	return nil
+}
+{{end}}
 `
 
 // EOF
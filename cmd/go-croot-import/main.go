@@ -0,0 +1,107 @@
+// go-croot-import converts CSV data into a ROOT tree:
+//
+//	$ go-croot-import [options] data.csv
+//	$ go-croot-import -o survey.root -t survey -types "run:int32,comment:string" data.csv
+//	$ cat data.csv | go-croot-import -o data.root
+//
+// the CSV data must start with a header line naming the columns.
+// the types of the columns are inferred from the first records (int64,
+// float64 or string) unless given with -types.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-hep/croot"
+)
+
+var (
+	oname  = flag.String("o", "out.root", "ROOT file to create")
+	tname  = flag.String("t", "tree", "name of the ROOT tree to create")
+	types  = flag.String("types", "", "comma-separated list of column:type overrides (bool, int8, ..., uint64, float32, float64 or string)")
+	sample = flag.Int("sample", 1000, "number of records used to infer the types of the columns")
+	comma  = flag.String("comma", ",", "field delimiter")
+)
+
+func main() {
+	flag.Parse()
+
+	opts := croot.ImportOptions{
+		Types:  make(map[string]reflect.Kind),
+		Sample: *sample,
+	}
+
+	if utf8.RuneCountInString(*comma) != 1 {
+		fmt.Fprintf(os.Stderr, "**error** invalid field delimiter [%s]\n", *comma)
+		os.Exit(1)
+	}
+	opts.Comma, _ = utf8.DecodeRuneInString(*comma)
+
+	for _, typ := range strings.Split(*types, ",") {
+		if typ = strings.TrimSpace(typ); typ == "" {
+			continue
+		}
+		i := strings.LastIndex(typ, ":")
+		if i < 0 {
+			fmt.Fprintf(os.Stderr, "**error** invalid type override [%s] (expected column:type)\n", typ)
+			os.Exit(1)
+		}
+		kind, ok := kinds[typ[i+1:]]
+		if !ok {
+			fmt.Fprintf(os.Stderr, "**error** invalid type [%s] for column [%s]\n", typ[i+1:], typ[:i])
+			os.Exit(1)
+		}
+		opts.Types[typ[:i]] = kind
+	}
+
+	var in io.Reader = os.Stdin
+	if flag.NArg() > 0 {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "**error** %v\n", err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	f, err := croot.OpenFile(*oname, "recreate", "go-croot-import-file", 1, 0)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "**error** %v\n", err)
+		os.Exit(1)
+	}
+
+	tree := croot.NewTree(*tname, *tname, 32)
+	n, err := croot.ImportCSV(in, tree, opts)
+	if err != nil {
+		f.Close("")
+		fmt.Fprintf(os.Stderr, "**error** %v\n", err)
+		os.Exit(1)
+	}
+
+	f.Write("", 0, 0)
+	f.Close("")
+	fmt.Fprintf(os.Stderr, ":: imported %d records into [%s:%s]\n", n, *oname, *tname)
+}
+
+// kinds maps the names of the types of the -types flag to their kind
+var kinds = map[string]reflect.Kind{
+	"bool":    reflect.Bool,
+	"int8":    reflect.Int8,
+	"int16":   reflect.Int16,
+	"int32":   reflect.Int32,
+	"int64":   reflect.Int64,
+	"uint8":   reflect.Uint8,
+	"uint16":  reflect.Uint16,
+	"uint32":  reflect.Uint32,
+	"uint64":  reflect.Uint64,
+	"float32": reflect.Float32,
+	"float64": reflect.Float64,
+	"string":  reflect.String,
+}
@@ -1,58 +1,264 @@
+// go-croot-ls lists the content of a ROOT file:
+//
+//	$ go-croot-ls [options] file.root
+//
+// all the keys are listed recursively (directories, trees, histograms, ...)
+// along with their class name, cycle and sizes.
+// trees are described down to their sub-branches and leaves.
 package main
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/go-hep/croot"
 )
 
 var (
-	fname = flag.String("f", "", "ROOT file to inspect")
-	tname = flag.String("t", "", "ROOT tree to inspect")
+	fname  = flag.String("f", "", "ROOT file to inspect")
+	tname  = flag.String("t", "", "ROOT tree to inspect (default: all the keys)")
+	sizes  = flag.Bool("sizes", false, "print the size and compression statistics of the branches")
+	dojson = flag.Bool("json", false, "print the listing as JSON")
 )
 
-func main() {
+// lsKey describes a key of a ROOT directory
+type lsKey struct {
+	Name   string  `json:"name"`
+	Title  string  `json:"title"`
+	Class  string  `json:"class"`
+	Cycle  int     `json:"cycle"`
+	Nbytes int64   `json:"nbytes"`
+	ObjLen int64   `json:"objlen"`
+	Keys   []lsKey `json:"keys,omitempty"`
+	Tree   *lsTree `json:"tree,omitempty"`
+}
+
+// lsTree describes a TTree
+type lsTree struct {
+	Entries  int64              `json:"entries"`
+	TotBytes int64              `json:"totbytes"`
+	ZipBytes int64              `json:"zipbytes"`
+	Branches []lsBranch         `json:"branches"`
+	Sizes    []croot.BranchSize `json:"sizes,omitempty"` // with -json only
+
+	tree croot.Tree // to print the size statistics as a table
+}
+
+// lsBranch describes a TBranch and its sub-branches
+type lsBranch struct {
+	Name     string     `json:"name"`
+	Class    string     `json:"class"`
+	Entries  int64      `json:"entries"`
+	Baskets  int        `json:"baskets"` // baskets of this branch only (not of its sub-branches)
+	TotBytes int64      `json:"totbytes"`
+	ZipBytes int64      `json:"zipbytes"`
+	Leaves   []lsLeaf   `json:"leaves,omitempty"`
+	Branches []lsBranch `json:"branches,omitempty"`
+}
+
+// lsLeaf describes a TLeaf
+type lsLeaf struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Len   int    `json:"len"`
+	Count string `json:"count,omitempty"` // name of the leaf holding the number of elements
+}
 
-	fmt.Printf(":: croot-ls ::\n")
+func main() {
 	flag.Parse()
 
-	if *fname == "" && len(os.Args) > 1 {
-		*fname = os.Args[1]
+	if *fname == "" && flag.NArg() > 0 {
+		*fname = flag.Arg(0)
 	}
 
-	if *fname == "" {
-		fmt.Printf("**error** you have to give a (valid) path to a ROOT file\n")
+	err := run(os.Stdout)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "**error** %v\n", err)
 		os.Exit(1)
 	}
+}
 
-	if *tname == "" {
-		fmt.Printf("**error** you have to give a TTree name to inspect off the ROOT file\n")
-		os.Exit(1)
+// run lists the content of the file given on the command line into w.
+func run(w io.Writer) error {
+	if *fname == "" {
+		return fmt.Errorf("you have to give a (valid) path to a ROOT file")
 	}
 
 	f, err := croot.OpenFile(*fname, "read", "go-croot-ls-file", 1, 0)
 	if err != nil {
-		fmt.Printf("**error** %v\n", err)
-		os.Exit(1)
+		return err
 	}
-
 	defer f.Close("")
 
-	fmt.Printf(":: file: %s\n", f.GetName())
-	tree := f.GetTree(*tname)
-	if tree == nil {
-		fmt.Printf("**error** no such tree [%s]\n", *tname)
-		os.Exit(1)
+	var keys []lsKey
+	if *tname != "" {
+		tree := f.GetTree(*tname)
+		if tree == nil {
+			return fmt.Errorf("no such tree [%s]", *tname)
+		}
+		keys = []lsKey{{
+			Name:  *tname,
+			Title: tree.GetTitle(),
+			Class: tree.ClassName(),
+			Tree:  describe_tree(tree),
+		}}
+	} else {
+		keys = list_keys(f, f, "")
 	}
-	fmt.Printf(":: tree: %s (entries=%v)\n", *tname, tree.GetEntries())
-	branches := tree.GetListOfBranches()
-	fmt.Printf(":: branches: %v\n", len(branches))
-	for i, b := range branches {
-		fmt.Printf("  br[%d]: %v\n", i, b.GetName())
-		b.Print("")
+
+	if *dojson {
+		out, err := json.MarshalIndent(struct {
+			File string  `json:"file"`
+			Keys []lsKey `json:"keys"`
+		}{f.GetName(), keys}, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", out)
+		return err
 	}
 
-	os.Exit(0)
+	fmt.Fprintf(w, ":: file: %s\n", f.GetName())
+	for _, k := range keys {
+		err = print_key(w, k, "")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// list_keys describes the keys of dir (located at path in f), recursively.
+func list_keys(f croot.File, dir croot.Directory, path string) []lsKey {
+	var keys []lsKey
+	for _, k := range dir.GetListOfKeys() {
+		key := lsKey{
+			Name:   k.Name,
+			Title:  k.Title,
+			Class:  k.ClassName,
+			Cycle:  k.Cycle,
+			Nbytes: k.Nbytes,
+			ObjLen: k.ObjLen,
+		}
+		namecycle := fmt.Sprintf("%s%s;%d", path, k.Name, k.Cycle)
+		switch k.ClassName {
+		case "TDirectory", "TDirectoryFile":
+			if sub, ok := f.Get(namecycle).(croot.Directory); ok {
+				key.Keys = list_keys(f, sub, path+k.Name+"/")
+			}
+		case "TTree", "TNtuple", "TNtupleD":
+			if tree := f.GetTree(namecycle); tree != nil {
+				key.Tree = describe_tree(tree)
+			}
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+func describe_tree(tree croot.Tree) *lsTree {
+	t := &lsTree{
+		tree:     tree,
+		Entries:  tree.GetEntries(),
+		TotBytes: tree.GetTotBytes(),
+		ZipBytes: tree.GetZipBytes(),
+	}
+	for _, b := range tree.GetListOfBranches() {
+		if b == nil {
+			continue
+		}
+		t.Branches = append(t.Branches, describe_branch(b))
+	}
+	if *sizes && *dojson {
+		t.Sizes = croot.SizeSummary(tree)
+	}
+	return t
+}
+
+func describe_branch(b croot.Branch) lsBranch {
+	br := lsBranch{
+		Name:     b.GetName(),
+		Class:    b.GetClassName(),
+		Entries:  b.GetEntries(),
+		Baskets:  b.GetWriteBasket(),
+		TotBytes: b.GetTotBytes("*"),
+		ZipBytes: b.GetZipBytes("*"),
+	}
+	// leaflist branches hold no class: report the type of the branch itself
+	if br.Class == "" {
+		br.Class = b.ClassName()
+	}
+	for _, sub := range b.GetListOfBranches() {
+		br.Branches = append(br.Branches, describe_branch(sub))
+	}
+	// the leaves of split branches are described by their sub-branches
+	if len(br.Branches) > 0 {
+		return br
+	}
+	for _, l := range b.GetListOfLeaves() {
+		if l == nil {
+			continue
+		}
+		leaf := lsLeaf{
+			Name: l.GetName(),
+			Type: l.GetTypeName(),
+			Len:  l.GetLenStatic(),
+		}
+		if count := l.GetLeafCount(); count != nil {
+			leaf.Count = count.GetName()
+		}
+		br.Leaves = append(br.Leaves, leaf)
+	}
+	return br
+}
+
+func print_key(w io.Writer, k lsKey, indent string) error {
+	fmt.Fprintf(w, "%s%s;%d\t%s\t%q\t(nbytes=%d, objlen=%d)\n",
+		indent, k.Name, k.Cycle, k.Class, k.Title, k.Nbytes, k.ObjLen,
+	)
+	for _, sub := range k.Keys {
+		err := print_key(w, sub, indent+"  ")
+		if err != nil {
+			return err
+		}
+	}
+	if k.Tree == nil {
+		return nil
+	}
+	fmt.Fprintf(w, "%s  entries=%d, totbytes=%d, zipbytes=%d\n",
+		indent, k.Tree.Entries, k.Tree.TotBytes, k.Tree.ZipBytes,
+	)
+	for _, b := range k.Tree.Branches {
+		print_branch(w, b, indent+"  ")
+	}
+	if !*sizes {
+		return nil
+	}
+	fmt.Fprintf(w, "%s  sizes:\n", indent)
+	return croot.WriteSizeSummary(w, k.Tree.tree)
+}
+
+func print_branch(w io.Writer, b lsBranch, indent string) {
+	leaves := make([]string, 0, len(b.Leaves))
+	for _, l := range b.Leaves {
+		switch {
+		case l.Count != "":
+			leaves = append(leaves, fmt.Sprintf("%s[%s]/%s", l.Name, l.Count, l.Type))
+		case l.Len > 1:
+			leaves = append(leaves, fmt.Sprintf("%s[%d]/%s", l.Name, l.Len, l.Type))
+		default:
+			leaves = append(leaves, fmt.Sprintf("%s/%s", l.Name, l.Type))
+		}
+	}
+	fmt.Fprintf(w, "%sbranch: %s\t%s\t[%s]\t(entries=%d, baskets=%d, totbytes=%d, zipbytes=%d)\n",
+		indent, b.Name, b.Class, strings.Join(leaves, ", "),
+		b.Entries, b.Baskets, b.TotBytes, b.ZipBytes,
+	)
+	for _, sub := range b.Branches {
+		print_branch(w, sub, indent+"  ")
+	}
 }
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/go-hep/croot"
+)
+
+type Det struct {
+	E float64
+	T float64
+}
+
+type Event struct {
+	I int64
+	A Det
+	B Det
+}
+
+// createFile writes a file with a histogram in a sub-directory, and a tree
+// with a split branch (evt) and a leaflist branch (x).
+func createFile(t *testing.T, fname string) {
+	f, err := croot.OpenFile(fname, "recreate", "go-croot-ls test file", 1, 0)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	defer f.Close("")
+
+	dir, err := f.Mkdir("dir", "a directory")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	if !dir.Cd("") {
+		t.Fatalf("could not cd into [dir]")
+	}
+	h := croot.NewH1F("h", "histo", 10, 0, 10)
+	h.Fill(1, 1)
+
+	if !f.Cd("") {
+		t.Fatalf("could not cd into [%s]", fname)
+	}
+	tree := croot.NewTree("tree", "split tree", 99)
+	var (
+		e Event
+		x float64
+	)
+	_, err = tree.Branch("evt", &e, 32000, 99)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	_, err = tree.Branch2("x", &x, "x/D", 32000)
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+	for i := 0; i != 100; i++ {
+		e.I = int64(i)
+		e.A = Det{E: float64(i), T: float64(2 * i)}
+		e.B = Det{E: float64(-i), T: float64(-2 * i)}
+		x = float64(i)
+		_, err = tree.Fill()
+		if err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+	f.Write("", 0, 0)
+}
+
+// runLs runs the command on fname with the given flags.
+func runLs(t *testing.T, name string, asJSON, withSizes bool) string {
+	*fname, *dojson, *sizes = name, asJSON, withSizes
+	defer func() {
+		*fname, *dojson, *sizes = "", false, false
+	}()
+
+	o := new(bytes.Buffer)
+	err := run(o)
+	if err != nil {
+		t.Fatalf("json=%v sizes=%v: %v", asJSON, withSizes, err)
+	}
+	return o.String()
+}
+
+func TestLs(t *testing.T) {
+	const fname = "go-croot-ls-test.root"
+	createFile(t, fname)
+	defer os.Remove(fname)
+
+	out := runLs(t, fname, false, true)
+	for _, want := range []string{
+		":: file: " + fname + "\n",
+		"dir;1\tTDirectoryFile\t",
+		"\n  h;1\tTH1F\t\"histo\"\t",
+		"tree;1\tTTree\t\"split tree\"\t",
+		"  entries=100, ",
+		"  branch: evt\t",
+		"    branch: ",
+		"  branch: x\tTBranch\t[x/Double_t]\t(entries=100, ",
+		"  sizes:\n",
+		"total",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "branch: evt\tTBranchElement\t") {
+		t.Errorf("expected the class of the object held by [evt]:\n%s", out)
+	}
+
+	// the JSON schema
+	var doc map[string]interface{}
+	err := json.Unmarshal([]byte(runLs(t, fname, true, true)), &doc)
+	if err != nil {
+		t.Fatalf("invalid JSON output: %v", err)
+	}
+	hasKeys := func(ctx string, obj interface{}, names ...string) map[string]interface{} {
+		m, ok := obj.(map[string]interface{})
+		if !ok {
+			t.Fatalf("%s: expected an object, got %T", ctx, obj)
+		}
+		for _, n := range names {
+			if _, ok := m[n]; !ok {
+				t.Errorf("%s: missing field %q in %v", ctx, n, m)
+			}
+		}
+		return m
+	}
+	list := func(ctx string, obj interface{}) []interface{} {
+		s, ok := obj.([]interface{})
+		if !ok || len(s) == 0 {
+			t.Fatalf("%s: expected a non-empty list, got %v", ctx, obj)
+		}
+		return s
+	}
+
+	hasKeys("file", doc, "file", "keys")
+	if doc["file"] != fname {
+		t.Errorf("file: expected %q, got %v", fname, doc["file"])
+	}
+	keys := make(map[string]map[string]interface{})
+	for _, k := range list("keys", doc["keys"]) {
+		k := hasKeys("key", k, "name", "title", "class", "cycle", "nbytes", "objlen")
+		keys[k["name"].(string)] = k
+	}
+
+	dir := keys["dir"]
+	if dir == nil || dir["class"] != "TDirectoryFile" {
+		t.Fatalf("dir: unexpected key %v", dir)
+	}
+	sub := list("dir.keys", dir["keys"])
+	if len(sub) != 1 {
+		t.Fatalf("dir.keys: expected 1 key, got %v", sub)
+	}
+	h := hasKeys("dir/h", sub[0], "name", "title", "class", "cycle", "nbytes", "objlen")
+	if h["name"] != "h" || h["class"] != "TH1F" || h["title"] != "histo" {
+		t.Errorf("dir/h: unexpected key %v", h)
+	}
+
+	if keys["tree"] == nil {
+		t.Fatalf("tree: missing key in %v", keys)
+	}
+	tree := hasKeys("tree", keys["tree"]["tree"], "entries", "totbytes", "zipbytes", "branches", "sizes")
+	if tree["entries"] != 100.0 {
+		t.Errorf("tree: expected 100 entries, got %v", tree["entries"])
+	}
+	branches := make(map[string]map[string]interface{})
+	for _, b := range list("tree.branches", tree["branches"]) {
+		b := hasKeys("branch", b, "name", "class", "entries", "baskets", "totbytes", "zipbytes")
+		branches[b["name"].(string)] = b
+	}
+	if evt := branches["evt"]; evt == nil {
+		t.Errorf("evt: missing branch in %v", branches)
+	} else {
+		if c := evt["class"]; c == "" || c == "TBranchElement" {
+			t.Errorf("evt: expected the class of the object held by the branch, got %v", c)
+		}
+		for _, b := range list("evt.branches", evt["branches"]) {
+			hasKeys("evt sub-branch", b, "name", "class", "entries", "baskets", "totbytes", "zipbytes")
+		}
+	}
+	if x := branches["x"]; x == nil {
+		t.Errorf("x: missing branch in %v", branches)
+	} else {
+		if x["class"] != "TBranch" {
+			t.Errorf("x: expected class TBranch, got %v", x["class"])
+		}
+		leaves := list("x.leaves", x["leaves"])
+		leaf := hasKeys("x leaf", leaves[0], "name", "type", "len")
+		if leaf["name"] != "x" || leaf["type"] != "Double_t" {
+			t.Errorf("x: unexpected leaf %v", leaf)
+		}
+	}
+	for _, bs := range list("tree.sizes", tree["sizes"]) {
+		hasKeys("size", bs, "name", "entries", "baskets", "totbytes", "zipbytes")
+	}
+}
@@ -0,0 +1,69 @@
+// go-croot-merge merges ROOT files, like ROOT's hadd:
+//
+//	$ go-croot-merge [options] out.root in1.root in2.root [...]
+//
+// trees are merged by name and histograms are added.
+// go-croot-merge exits with a non-zero status if the merge failed, e.g.
+// when a key holds objects of different types in different inputs.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/go-hep/croot"
+)
+
+var (
+	force    = flag.Bool("f", false, "overwrite the output file if it already exists")
+	compress = flag.Int("c", croot.DefaultMergeOptions.Compress, "compression level of the output file")
+	keys     = flag.String("k", "", "comma-separated list of the names of the keys to merge (default: all)")
+	parallel = flag.Int("j", 1, "number of concurrent partial merges")
+	verbose  = flag.Bool("v", false, "enable verbose mode")
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: go-croot-merge [options] out.root in1.root [in2.root [...]]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() < 2 {
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	opts := croot.MergeOptions{
+		Compress: *compress,
+		Parallel: *parallel,
+		Force:    *force,
+		Verbose:  *verbose,
+	}
+	if *keys != "" {
+		for _, k := range strings.Split(*keys, ",") {
+			if k = strings.TrimSpace(k); k != "" {
+				opts.Keys = append(opts.Keys, k)
+			}
+		}
+	}
+
+	out := flag.Arg(0)
+	inputs := flag.Args()[1:]
+	if *verbose {
+		fmt.Printf(":: merging %d files into [%s]...\n", len(inputs), out)
+	}
+
+	err := croot.MergeFilesWith(out, opts, inputs...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "**error** %v\n", err)
+		os.Exit(1)
+	}
+
+	if *verbose {
+		fmt.Printf(":: merging %d files into [%s]... [done]\n", len(inputs), out)
+	}
+	os.Exit(0)
+}
@@ -0,0 +1,45 @@
+package croot
+
+// CompressionAlgorithm is a compression algorithm supported by ROOT
+type CompressionAlgorithm int
+
+const (
+	GlobalCompression CompressionAlgorithm = 0 // use the global setting
+	ZLIB              CompressionAlgorithm = 1
+	LZMA              CompressionAlgorithm = 2
+	OldCompression    CompressionAlgorithm = 3 // ROOT's legacy ZLIB
+	LZ4               CompressionAlgorithm = 4
+	ZSTD              CompressionAlgorithm = 5
+)
+
+func (alg CompressionAlgorithm) String() string {
+	switch alg {
+	case GlobalCompression:
+		return "global"
+	case ZLIB:
+		return "zlib"
+	case LZMA:
+		return "lzma"
+	case OldCompression:
+		return "old"
+	case LZ4:
+		return "lz4"
+	case ZSTD:
+		return "zstd"
+	}
+	return "unknown"
+}
+
+// CompressionSettings returns the ROOT compression settings for the given
+// algorithm and level (from 0: no compression, to 9: maximal compression).
+func CompressionSettings(alg CompressionAlgorithm, level int) int {
+	return int(alg)*100 + level
+}
+
+// CompressionAlgorithmOf returns the algorithm and level of the given
+// ROOT compression settings.
+func CompressionAlgorithmOf(settings int) (CompressionAlgorithm, int) {
+	return CompressionAlgorithm(settings / 100), settings % 100
+}
+
+// EOF
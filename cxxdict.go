@@ -0,0 +1,267 @@
+package croot
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// types registered with RegisterType, in registration order
+var registered_types []reflect.Type
+
+// RegisteredTypes returns the types registered with RegisterType.
+func RegisteredTypes() []reflect.Type {
+	return append([]reflect.Type(nil), registered_types...)
+}
+
+// cxx_types maps the kinds of builtins to the C++ types of their Reflex
+// dictionary (see rflx_type_from)
+var cxx_types = map[reflect.Kind]string{
+	reflect.Int:     "int",
+	reflect.Int8:    "int8_t",
+	reflect.Int16:   "int16_t",
+	reflect.Int32:   "int32_t",
+	reflect.Int64:   "int64_t",
+	reflect.Uint:    "unsigned int",
+	reflect.Uint8:   "uint8_t",
+	reflect.Uint16:  "uint16_t",
+	reflect.Uint32:  "uint32_t",
+	reflect.Uint64:  "uint64_t",
+	reflect.Uintptr: "uintptr_t",
+	reflect.Float32: "float",
+	reflect.Float64: "double",
+}
+
+// GenCxxDict writes into dir the C++ declarations of types (or of the types
+// registered with RegisterType if none is given), so ROOT files written
+// from Go can be read natively once a dictionary is compiled for them:
+//   - name.h, the header declaring the types,
+//   - nameLinkDef.h, the LinkDef file for rootcling (or rootcint),
+//   - name_selection.xml, the selection file for genreflex.
+//
+// The declarations follow the layout of the runtime dictionaries: structs
+// are declared in the global namespace, strings as golang::string and
+// slices as golang::slice<T> (see goedmdict.h), T being the name of the Go
+// element type.
+// Only slices of builtins are supported, booleans and complex numbers are
+// not.
+func GenCxxDict(dir, name string, types ...reflect.Type) error {
+	if len(types) == 0 {
+		types = registered_types
+	}
+	if len(types) == 0 {
+		return fmt.Errorf("croot.GenCxxDict: no type to declare")
+	}
+
+	d := &cxx_dict{name: name, seen: make(map[string]bool)}
+	for _, t := range types {
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		err := d.add(t)
+		if err != nil {
+			return fmt.Errorf("croot.GenCxxDict: type [%v]: %v", t, err)
+		}
+	}
+
+	for _, f := range []struct {
+		name string
+		gen  func() []byte
+	}{
+		{name + ".h", d.header},
+		{name + "LinkDef.h", d.linkdef},
+		{name + "_selection.xml", d.selection},
+	} {
+		err := ioutil.WriteFile(filepath.Join(dir, f.name), f.gen(), 0644)
+		if err != nil {
+			return fmt.Errorf("croot.GenCxxDict: %v", err)
+		}
+	}
+	return nil
+}
+
+// cxx_dict collects the C++ classes declaring Go types
+type cxx_dict struct {
+	name    string
+	slices  []reflect.Type // element types of the golang::slice<T> classes
+	structs []reflect.Type // in dependency order
+	seen    map[string]bool
+}
+
+// add declares t and the types it depends on.
+func (d *cxx_dict) add(t reflect.Type) error {
+	switch t.Kind() {
+	case reflect.Array, reflect.Ptr:
+		return d.add(t.Elem())
+
+	case reflect.String:
+		return nil
+
+	case reflect.Slice:
+		elem := t.Elem()
+		if _, ok := cxx_types[elem.Kind()]; !ok {
+			return fmt.Errorf("unsupported slice element type [%v]", elem)
+		}
+		if !d.seen[cxx_type(t)] {
+			d.seen[cxx_type(t)] = true
+			d.slices = append(d.slices, elem)
+		}
+		return nil
+
+	case reflect.Struct:
+		if t.Name() == "" {
+			return fmt.Errorf("unsupported anonymous struct [%v]", t)
+		}
+		if d.seen[t.Name()] {
+			return nil
+		}
+		d.seen[t.Name()] = true
+		for i := 0; i < t.NumField(); i++ {
+			err := d.add(t.Field(i).Type)
+			if err != nil {
+				return fmt.Errorf("field [%s]: %v", t.Field(i).Name, err)
+			}
+		}
+		d.structs = append(d.structs, t)
+		return nil
+	}
+
+	if _, ok := cxx_types[t.Kind()]; !ok {
+		return fmt.Errorf("unsupported type [%v]", t)
+	}
+	return nil
+}
+
+// cxx_type returns the name of the C++ type declaring t.
+func cxx_type(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return cxx_type(t.Elem()) + "*"
+	case reflect.String:
+		return "golang::string"
+	case reflect.Slice:
+		return reflect_name2rflx(t)
+	case reflect.Struct:
+		return to_cxx_name(t)
+	}
+	return cxx_types[t.Kind()]
+}
+
+// cxx_decl returns the C++ declaration of the data member name of type t.
+func cxx_decl(t reflect.Type, name string) string {
+	dims := ""
+	for t.Kind() == reflect.Array {
+		dims += fmt.Sprintf("[%d]", t.Len())
+		t = t.Elem()
+	}
+	return fmt.Sprintf("%s %s%s;", cxx_type(t), name, dims)
+}
+
+// header_guard returns the include guard of the header
+func (d *cxx_dict) header_guard() string {
+	return "CROOT_GEN_" + strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, d.name) + "_H"
+}
+
+// classes returns the names of the declared classes
+func (d *cxx_dict) classes() []string {
+	names := []string{"golang::string"}
+	for _, elem := range d.slices {
+		names = append(names, cxx_type(reflect.SliceOf(elem)))
+	}
+	for _, t := range d.structs {
+		names = append(names, cxx_type(t))
+	}
+	return names
+}
+
+func (d *cxx_dict) header() []byte {
+	o := new(bytes.Buffer)
+	guard := d.header_guard()
+	fmt.Fprintf(o, "// automatically generated by croot.GenCxxDict!\n\n")
+	fmt.Fprintf(o, "#ifndef %s\n#define %s 1\n\n#include <stdint.h>\n\n", guard, guard)
+
+	// golang::slice<T> is named after the Go element type: its name is
+	// declared as an (incomplete) tag, unless it is a C++ type.
+	tags := 0
+	for _, elem := range d.slices {
+		if elem.Name() == "int" {
+			continue
+		}
+		if tags == 0 {
+			fmt.Fprintf(o, "// Go element types of the golang::slice classes\n")
+		}
+		fmt.Fprintf(o, "struct %s;\n", elem.Name())
+		tags++
+	}
+	if tags > 0 {
+		fmt.Fprintf(o, "\n")
+	}
+
+	for _, t := range d.structs {
+		fmt.Fprintf(o, "struct %s;\n", cxx_type(t))
+	}
+	if len(d.structs) > 0 {
+		fmt.Fprintf(o, "\n")
+	}
+
+	fmt.Fprintf(o, "namespace golang {\n")
+	fmt.Fprintf(o, "  // layout of Go strings\n")
+	fmt.Fprintf(o, "  struct string {\n    int32_t Len;\n    char *Data; //[Len]\n  };\n\n")
+	fmt.Fprintf(o, "  // layout of Go slices\n")
+	fmt.Fprintf(o, "  template <typename T>\n  struct slice;\n")
+	for _, elem := range d.slices {
+		fmt.Fprintf(o, "\n  template <>\n  struct slice<%s> {\n", elem.Name())
+		fmt.Fprintf(o, "    int32_t Len;\n    int32_t Cap;\n")
+		fmt.Fprintf(o, "    %s *Data; //[Len]\n  };\n", cxx_types[elem.Kind()])
+	}
+	fmt.Fprintf(o, "}\n")
+
+	for _, t := range d.structs {
+		fmt.Fprintf(o, "\nstruct %s {\n", cxx_type(t))
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			fmt.Fprintf(o, "  %s\n", cxx_decl(f.Type, f.Name))
+		}
+		fmt.Fprintf(o, "};\n")
+	}
+
+	fmt.Fprintf(o, "\n#endif /* !%s */\n", guard)
+	return o.Bytes()
+}
+
+func (d *cxx_dict) linkdef() []byte {
+	o := new(bytes.Buffer)
+	fmt.Fprintf(o, "// automatically generated by croot.GenCxxDict!\n\n")
+	fmt.Fprintf(o, "#if defined(__CINT__) || defined(__CLING__)\n\n")
+	fmt.Fprintf(o, "#pragma link off all globals;\n")
+	fmt.Fprintf(o, "#pragma link off all classes;\n")
+	fmt.Fprintf(o, "#pragma link off all functions;\n\n")
+	fmt.Fprintf(o, "#pragma link C++ namespace golang;\n")
+	for _, name := range d.classes() {
+		fmt.Fprintf(o, "#pragma link C++ class %s+;\n", name)
+	}
+	fmt.Fprintf(o, "\n#endif\n")
+	return o.Bytes()
+}
+
+func (d *cxx_dict) selection() []byte {
+	o := new(bytes.Buffer)
+	fmt.Fprintf(o, "<!-- automatically generated by croot.GenCxxDict! -->\n")
+	fmt.Fprintf(o, "<lcgdict>\n")
+	for _, name := range d.classes() {
+		fmt.Fprintf(o, "  <class name=\"%s\" />\n", name)
+	}
+	fmt.Fprintf(o, "</lcgdict>\n")
+	return o.Bytes()
+}
+
+// EOF
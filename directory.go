@@ -0,0 +1,150 @@
+package croot
+
+// #include "croot/croot.h"
+//
+// #include <stdlib.h>
+// #include <string.h>
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Key describes an object stored in a File or a Directory
+type Key struct {
+	Name      string
+	Title     string
+	ClassName string
+	Cycle     int
+	Nbytes    int64 // size of the object on file, in bytes
+	ObjLen    int64 // uncompressed size of the object, in bytes
+}
+
+// Directory
+type Directory interface {
+	Object
+
+	Cd(path string) bool
+	Get(namecycle string) Object
+	GetListOfKeys() []Key
+	Mkdir(name, title string) (Directory, error)
+}
+
+type directory_impl struct {
+	c C.CRoot_Directory
+}
+
+func (d *directory_impl) cptr() C.CRoot_Object {
+	return (C.CRoot_Object)(d.c)
+}
+
+func (d *directory_impl) as_tobject() *object_impl {
+	return &object_impl{d.cptr()}
+}
+
+func (d *directory_impl) ClassName() string {
+	return d.as_tobject().ClassName()
+}
+
+func (d *directory_impl) Clone(opt Option) Object {
+	return d.as_tobject().Clone(opt)
+}
+
+func (d *directory_impl) FindObject(name string) Object {
+	return d.as_tobject().FindObject(name)
+}
+
+func (d *directory_impl) GetName() string {
+	return d.as_tobject().GetName()
+}
+
+func (d *directory_impl) GetTitle() string {
+	return d.as_tobject().GetTitle()
+}
+
+func (d *directory_impl) InheritsFrom(clsname string) bool {
+	return d.as_tobject().InheritsFrom(clsname)
+}
+
+func (d *directory_impl) Print(option Option) {
+	d.as_tobject().Print(option)
+}
+
+func (d *directory_impl) Cd(path string) bool {
+	c_path := C.CString(path)
+	defer C.free(unsafe.Pointer(c_path))
+	return c2bool(C.CRoot_Directory_cd(d.c, c_path))
+}
+
+func (d *directory_impl) Get(namecycle string) Object {
+	c_name := C.CString(namecycle)
+	defer C.free(unsafe.Pointer(c_name))
+	o := C.CRoot_Directory_Get(d.c, c_name)
+	if o == nil {
+		return nil
+	}
+	obj := &object_impl{o}
+	if _, ok := cnvmap[obj.ClassName()]; ok {
+		return to_gocroot(obj)
+	}
+	return obj
+}
+
+// GetListOfKeys returns the description of the keys of the directory.
+func (d *directory_impl) GetListOfKeys() []Key {
+	return get_list_of_keys(d.c)
+}
+
+// Mkdir creates a new sub-directory.
+func (d *directory_impl) Mkdir(name, title string) (Directory, error) {
+	return mkdir(d.c, name, title)
+}
+
+func mkdir(c C.CRoot_Directory, name, title string) (Directory, error) {
+	c_name := C.CString(name)
+	defer C.free(unsafe.Pointer(c_name))
+	c_title := C.CString(title)
+	defer C.free(unsafe.Pointer(c_title))
+	dir := C.CRoot_Directory_mkdir(c, c_name, c_title)
+	if dir == nil {
+		return nil, fmt.Errorf("croot.Directory.Mkdir: could not create directory [%s]", name)
+	}
+	return &directory_impl{c: dir}, nil
+}
+
+func get_list_of_keys(c C.CRoot_Directory) []Key {
+	n := int(C.CRoot_Directory_GetNkeys(c))
+	keys := make([]Key, 0, n)
+	for i := 0; i < n; i++ {
+		var c_name, c_title, c_class *C.char
+		var c_cycle C.int16_t
+		var c_nbytes, c_objlen C.int32_t
+		ok := C.CRoot_Directory_GetKey(
+			c, C.int32_t(i),
+			&c_name, &c_title, &c_class, &c_cycle,
+			&c_nbytes, &c_objlen,
+		)
+		if !c2bool(ok) {
+			break
+		}
+		keys = append(keys, Key{
+			Name:      C.GoString(c_name),
+			Title:     C.GoString(c_title),
+			ClassName: C.GoString(c_class),
+			Cycle:     int(c_cycle),
+			Nbytes:    int64(c_nbytes),
+			ObjLen:    int64(c_objlen),
+		})
+	}
+	return keys
+}
+
+func init() {
+	cnvmap["TDirectory"] = func(o c_object) Object {
+		return &directory_impl{c: (C.CRoot_Directory)(o.cptr())}
+	}
+	cnvmap["TDirectoryFile"] = cnvmap["TDirectory"]
+}
+
+// EOF
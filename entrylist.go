@@ -0,0 +1,177 @@
+package croot
+
+// #include "croot/croot.h"
+//
+// #include <stdlib.h>
+// #include <string.h>
+import "C"
+
+import (
+	"fmt"
+	"math"
+	"unsafe"
+)
+
+// EntryList is a list of selected entries of a Tree or Chain.
+//
+// Once attached to a Tree with SetEntryList, the selected entries are
+// visited with:
+//
+//	for i := int64(0); tree.GetEntryFromList(i, 1) > 0; i++ {
+//	    // ...
+//	}
+type EntryList interface {
+	Object
+
+	Contains(entry int64) bool
+	Delete()
+	Enter(entry int64) bool
+	GetEntry(index int64) int64
+	GetN() int64
+	SetTree(tree Tree)
+
+	// Write writes the list into the current directory (see File.Cd)
+	Write(name string, option, bufsize int) int
+}
+
+type entrylist_impl struct {
+	c C.CRoot_EntryList
+}
+
+func (el *entrylist_impl) cptr() C.CRoot_Object {
+	return (C.CRoot_Object)(el.c)
+}
+
+func (el *entrylist_impl) as_tobject() *object_impl {
+	return &object_impl{el.cptr()}
+}
+
+func (el *entrylist_impl) ClassName() string {
+	return el.as_tobject().ClassName()
+}
+
+func (el *entrylist_impl) Clone(opt Option) Object {
+	return el.as_tobject().Clone(opt)
+}
+
+func (el *entrylist_impl) FindObject(name string) Object {
+	return el.as_tobject().FindObject(name)
+}
+
+func (el *entrylist_impl) GetName() string {
+	return el.as_tobject().GetName()
+}
+
+func (el *entrylist_impl) GetTitle() string {
+	return el.as_tobject().GetTitle()
+}
+
+func (el *entrylist_impl) InheritsFrom(clsname string) bool {
+	return el.as_tobject().InheritsFrom(clsname)
+}
+
+func (el *entrylist_impl) Print(option Option) {
+	el.as_tobject().Print(option)
+}
+
+func (el *entrylist_impl) Write(name string, option, bufsize int) int {
+	return el.as_tobject().Write(name, option, bufsize)
+}
+
+// NewEntryList creates a new, empty, list of entries
+func NewEntryList(name, title string) EntryList {
+	c_name := C.CString(name)
+	defer C.free(unsafe.Pointer(c_name))
+	c_title := C.CString(title)
+	defer C.free(unsafe.Pointer(c_title))
+
+	c := C.CRoot_EntryList_new(c_name, c_title, nil)
+	return &entrylist_impl{c: c}
+}
+
+// NewEntryListFromSelection creates the list of entries of tree passing
+// the selection expression (e.g. "pt > 20 && abs(eta) < 2.5")
+func NewEntryListFromSelection(tree Tree, name, selection string) (EntryList, error) {
+	c_name := C.CString(name)
+	defer C.free(unsafe.Pointer(c_name))
+	c_sel := C.CString(selection)
+	defer C.free(unsafe.Pointer(c_sel))
+
+	c_tree := (C.CRoot_Tree)(unsafe.Pointer(tree.(c_object).cptr()))
+	c := C.CRoot_EntryList_newFromSelection(c_tree, c_name, c_sel)
+	if c == nil {
+		return nil, fmt.Errorf("croot.NewEntryListFromSelection: could not apply selection [%s]", selection)
+	}
+	return &entrylist_impl{c: c}, nil
+}
+
+// NewEntryListFunc creates the list of entries of tree for which keep
+// returns true. Each entry is loaded with tree.GetEntry(entry, 1) before
+// calling keep, so values bound with SetBranchAddress are up to date.
+func NewEntryListFunc(tree Tree, name string, keep func(entry int64) bool) (EntryList, error) {
+	c_name := C.CString(name)
+	defer C.free(unsafe.Pointer(c_name))
+	c_title := C.CString(name)
+	defer C.free(unsafe.Pointer(c_title))
+
+	c_tree := (C.CRoot_Tree)(unsafe.Pointer(tree.(c_object).cptr()))
+	c := C.CRoot_EntryList_new(c_name, c_title, c_tree)
+	el := &entrylist_impl{c: c}
+
+	nentries := tree.GetEntries()
+	for i := int64(0); i < nentries; i++ {
+		if tree.GetEntry(i, 1) <= 0 {
+			el.Delete()
+			return nil, fmt.Errorf("croot.NewEntryListFunc: could not read entry [%d]", i)
+		}
+		if keep(i) {
+			C.CRoot_EntryList_Enter(c, C.int64_t(i), c_tree)
+		}
+	}
+	return el, nil
+}
+
+func (el *entrylist_impl) Contains(entry int64) bool {
+	return int(C.CRoot_EntryList_Contains(el.c, C.int64_t(entry), nil)) != 0
+}
+
+func (el *entrylist_impl) Delete() {
+	C.CRoot_EntryList_delete(el.c)
+	el.c = nil
+}
+
+func (el *entrylist_impl) Enter(entry int64) bool {
+	return c2bool(C.CRoot_EntryList_Enter(el.c, C.int64_t(entry), nil))
+}
+
+// GetEntry returns the entry number of the index-th selected entry, or -1
+// if there is none.
+// Indices are limited to 32 bits by TEntryList.
+func (el *entrylist_impl) GetEntry(index int64) int64 {
+	if index < 0 || index > math.MaxInt32 {
+		return -1
+	}
+	return int64(C.CRoot_EntryList_GetEntry(el.c, C.int32_t(index)))
+}
+
+func (el *entrylist_impl) GetN() int64 {
+	return int64(C.CRoot_EntryList_GetN(el.c))
+}
+
+func (el *entrylist_impl) SetTree(tree Tree) {
+	c_tree := (C.CRoot_Tree)(unsafe.Pointer(tree.(c_object).cptr()))
+	C.CRoot_EntryList_SetTree(el.c, c_tree)
+}
+
+func init() {
+	cnvmap["TEntryList"] = func(o c_object) Object {
+		return &entrylist_impl{c: (C.CRoot_EntryList)(o.cptr())}
+	}
+	// legacy TEventList are converted to a TEntryList
+	cnvmap["TEventList"] = func(o c_object) Object {
+		c := C.CRoot_EntryList_newFromEventList((C.CRoot_EventList)(o.cptr()))
+		return &entrylist_impl{c: c}
+	}
+}
+
+// EOF
@@ -0,0 +1,256 @@
+package croot
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ExportOptions selects the content of a Tree written out by the Export
+// functions
+type ExportOptions struct {
+	Branches  []string // branches to export (all of them if empty)
+	Selection string   // expression entries have to pass (e.g. "x>0 && n==2")
+	First     int64    // first entry to export
+	N         int64    // maximum number of entries to export (all of them if <= 0)
+
+	// Explode writes a CSV row per element of the array columns, instead of
+	// a column per element of fixed-size arrays. Scalars are repeated on
+	// each row.
+	Explode bool
+}
+
+// ExportCSV writes the entries of t to w as CSV, with a header line naming
+// the columns, and returns the number of exported entries.
+// Fixed-size arrays are flattened into a column per element ("p[0]",
+// "p[1]", ...) while variable-size arrays are written as a single cell of
+// space-separated values, unless opts.Explode is set.
+// Entries are read and written one at a time.
+func ExportCSV(w io.Writer, t Tree, opts ExportOptions) (int64, error) {
+	cw := csv.NewWriter(w)
+	n, err := export_entries(t, opts, func(r *Reader) error {
+		var header []string
+		for _, col := range r.Columns() {
+			if !opts.Explode && col.Type.Kind() == reflect.Array {
+				for i := 0; i < col.Type.Len(); i++ {
+					header = append(header, fmt.Sprintf("%s[%d]", col.Name, i))
+				}
+				continue
+			}
+			header = append(header, col.Name)
+		}
+		return cw.Write(header)
+	}, func(vals []interface{}) error {
+		if opts.Explode {
+			return write_csv_exploded(cw, vals)
+		}
+		var rec []string
+		for _, v := range vals {
+			rv := reflect.ValueOf(v)
+			switch rv.Kind() {
+			case reflect.Array:
+				for j := 0; j < rv.Len(); j++ {
+					rec = append(rec, format_value(rv.Index(j)))
+				}
+			case reflect.Slice:
+				elems := make([]string, rv.Len())
+				for j := range elems {
+					elems[j] = format_value(rv.Index(j))
+				}
+				rec = append(rec, strings.Join(elems, " "))
+			default:
+				rec = append(rec, format_value(rv))
+			}
+		}
+		return cw.Write(rec)
+	})
+	cw.Flush()
+	if err == nil {
+		err = cw.Error()
+	}
+	return n, err
+}
+
+// write_csv_exploded writes a CSV record per element of the longest array
+// of vals.
+func write_csv_exploded(cw *csv.Writer, vals []interface{}) error {
+	nrows := 1
+	for _, v := range vals {
+		rv := reflect.ValueOf(v)
+		if k := rv.Kind(); (k == reflect.Array || k == reflect.Slice) && rv.Len() > nrows {
+			nrows = rv.Len()
+		}
+	}
+	rec := make([]string, len(vals))
+	for i := 0; i < nrows; i++ {
+		for j, v := range vals {
+			rv := reflect.ValueOf(v)
+			switch rv.Kind() {
+			case reflect.Array, reflect.Slice:
+				rec[j] = ""
+				if i < rv.Len() {
+					rec[j] = format_value(rv.Index(i))
+				}
+			default:
+				rec[j] = format_value(rv)
+			}
+		}
+		err := cw.Write(rec)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// format_value returns the textual representation of a scalar value.
+func format_value(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 32)
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case reflect.String:
+		return v.String()
+	}
+	return fmt.Sprintf("%v", v.Interface())
+}
+
+// ExportNDJSON writes the entries of t to w as newline-delimited JSON (a
+// JSON object per entry, with the columns as keys, in order) and returns
+// the number of exported entries.
+// NaNs and infinities, which have no JSON representation, are written as
+// strings.
+// Entries are read and written one at a time.
+func ExportNDJSON(w io.Writer, t Tree, opts ExportOptions) (int64, error) {
+	bw := bufio.NewWriter(w)
+	var keys []string
+	n, err := export_entries(t, opts, func(r *Reader) error {
+		for _, col := range r.Columns() {
+			key, err := json.Marshal(col.Name)
+			if err != nil {
+				return err
+			}
+			keys = append(keys, string(key))
+		}
+		return nil
+	}, func(vals []interface{}) error {
+		bw.WriteByte('{')
+		for i, v := range vals {
+			if i > 0 {
+				bw.WriteByte(',')
+			}
+			bw.WriteString(keys[i])
+			bw.WriteByte(':')
+			bw.Write(json_value(reflect.ValueOf(v)))
+		}
+		_, err := bw.WriteString("}\n")
+		return err
+	})
+	if ferr := bw.Flush(); err == nil {
+		err = ferr
+	}
+	return n, err
+}
+
+// json_value returns the JSON encoding of v.
+func json_value(v reflect.Value) []byte {
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return []byte(strconv.Quote(strconv.FormatFloat(f, 'g', -1, 64)))
+		}
+	case reflect.Array, reflect.Slice:
+		elems := make([]string, v.Len())
+		for i := range elems {
+			elems[i] = string(json_value(v.Index(i)))
+		}
+		return []byte("[" + strings.Join(elems, ",") + "]")
+	}
+	o, err := json.Marshal(v.Interface())
+	if err != nil {
+		return []byte(strconv.Quote(fmt.Sprintf("%v", v.Interface())))
+	}
+	return o
+}
+
+// export_entries reads the entries of t selected by opts and hands their
+// values to write. header is called once the columns are known.
+func export_entries(t Tree, opts ExportOptions, header func(r *Reader) error, write func(vals []interface{}) error) (int64, error) {
+	r, err := NewReader(t, opts.Branches...)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	var sel Formula
+	if opts.Selection != "" {
+		sel, err = NewFormula("croot-export-selection", opts.Selection, t)
+		if err != nil {
+			return 0, err
+		}
+		defer sel.Delete()
+		// the branches of the selection (and their counts) have to be
+		// read as well.
+		for _, leaf := range sel.GetLeaves() {
+			t.SetBranchStatus(leaf.GetBranch().GetName(), true)
+			if count := leaf.GetLeafCount(); count != nil {
+				t.SetBranchStatus(count.GetBranch().GetName(), true)
+			}
+		}
+	}
+
+	err = header(r)
+	if err != nil {
+		return 0, err
+	}
+
+	end := t.GetEntries()
+	if opts.N > 0 && opts.First+opts.N < end {
+		end = opts.First + opts.N
+	}
+
+	n := int64(0)
+	for i := opts.First; i < end; i++ {
+		vals, err := r.Read(i)
+		if err != nil {
+			return n, err
+		}
+		if sel != nil && !selected(sel) {
+			continue
+		}
+		err = write(vals)
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
+
+// selected returns whether the current entry passes the selection: an
+// entry passes if any of the instances of the selection is non-zero.
+func selected(sel Formula) bool {
+	n := sel.GetNdata()
+	for i := 0; i < n; i++ {
+		if sel.EvalInstance(i) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// EOF
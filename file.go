@@ -16,10 +16,16 @@ type File interface {
 
 	Cd(path string) bool
 	Close(option string)
+	GetBytesRead() int64
+	GetCompressionSettings() int
 	GetFd() int
 	Get(namecycle string) Object
+	GetListOfKeys() []Key
+	GetReadCalls() int
 	GetTree(namecycle string) Tree //FIXME: should use Get+type-cast
 	IsOpen() bool
+	Mkdir(name, title string) (Directory, error)
+	SetCompressionSettings(settings int)
 	Write(name string, opt, bufsiz int) int
 }
 
@@ -63,6 +69,9 @@ func (f *file_impl) Print(option Option) {
 	f.as_tobject().Print(option)
 }
 
+// OpenFile opens or creates the ROOT file name.
+// compress is either a compression level (using ROOT's default algorithm)
+// or the compression settings returned by CompressionSettings.
 func OpenFile(name, option, title string, compress, netopt int) (File, error) {
 	c_name := C.CString(name)
 	defer C.free(unsafe.Pointer(c_name))
@@ -91,6 +100,15 @@ func (f *file_impl) Close(option string) {
 	C.CRoot_File_Close(f.c, (*C.CRoot_Option)(c_option))
 }
 
+// GetBytesRead returns the number of bytes read from the file so far.
+func (f *file_impl) GetBytesRead() int64 {
+	return int64(C.CRoot_File_GetBytesRead(f.c))
+}
+
+func (f *file_impl) GetCompressionSettings() int {
+	return int(C.CRoot_File_GetCompressionSettings(f.c))
+}
+
 func (f *file_impl) GetFd() int {
 	return int(C.CRoot_File_GetFd(f.c))
 }
@@ -102,7 +120,21 @@ func (f *file_impl) Get(namecycle string) Object {
 	if o == nil {
 		return nil
 	}
-	return &object_impl{o}
+	obj := &object_impl{o}
+	if _, ok := cnvmap[obj.ClassName()]; ok {
+		return to_gocroot(obj)
+	}
+	return obj
+}
+
+// GetListOfKeys returns the description of the top-level keys of the file.
+func (f *file_impl) GetListOfKeys() []Key {
+	return get_list_of_keys((C.CRoot_Directory)(f.c))
+}
+
+// GetReadCalls returns the number of read calls issued on the file so far.
+func (f *file_impl) GetReadCalls() int {
+	return int(C.CRoot_File_GetReadCalls(f.c))
 }
 
 func (f *file_impl) GetTree(namecycle string) Tree {
@@ -118,6 +150,17 @@ func (f *file_impl) IsOpen() bool {
 	return c2bool(C.CRoot_File_IsOpen(f.c))
 }
 
+// Mkdir creates a new top-level directory.
+func (f *file_impl) Mkdir(name, title string) (Directory, error) {
+	return mkdir((C.CRoot_Directory)(f.c), name, title)
+}
+
+// SetCompressionSettings sets the compression algorithm and level used
+// for the objects written from now on (see CompressionSettings).
+func (f *file_impl) SetCompressionSettings(settings int) {
+	C.CRoot_File_SetCompressionSettings(f.c, C.int32_t(settings))
+}
+
 //func (f *file_impl) ReadBuffer(buf, pos, len)
 //func (f *file_impl) ReadBuffers
 //func (f *file_impl) WriteBuffer
@@ -0,0 +1,100 @@
+package croot
+
+// #include "croot/croot.h"
+//
+// #include <stdlib.h>
+// #include <string.h>
+import "C"
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Formula evaluates a ROOT expression (e.g. "sqrt(px*px+py*py)") against
+// the current entry of a Tree or Chain.
+//
+// The entry is selected with Tree.GetEntry or Tree.LoadTree; GetNdata
+// should then be called before EvalInstance.
+type Formula interface {
+	Delete()
+	GetExpFormula() string
+	GetNdata() int
+	EvalInstance(i int) float64
+	GetLeaves() []Leaf
+}
+
+type formula_impl struct {
+	c       C.CRoot_TreeFormula
+	tree    C.CRoot_Tree
+	expr    string
+	treenum int
+}
+
+// NewFormula compiles expr against the branches of tree.
+func NewFormula(name, expr string, tree Tree) (Formula, error) {
+	c_name := C.CString(name)
+	defer C.free(unsafe.Pointer(c_name))
+	c_expr := C.CString(expr)
+	defer C.free(unsafe.Pointer(c_expr))
+
+	c_tree := (C.CRoot_Tree)(unsafe.Pointer(tree.(c_object).cptr()))
+	c := C.CRoot_TreeFormula_new(c_name, c_expr, c_tree)
+	if c == nil {
+		return nil, fmt.Errorf("croot.NewFormula: could not create formula [%s]", expr)
+	}
+
+	// TTreeFormula flags compilation errors with a zero dimension.
+	if int(C.CRoot_TreeFormula_GetNdim(c)) <= 0 {
+		C.CRoot_TreeFormula_delete(c)
+		return nil, fmt.Errorf("croot.NewFormula: invalid expression [%s]", expr)
+	}
+
+	f := &formula_impl{
+		c:       c,
+		tree:    c_tree,
+		expr:    expr,
+		treenum: int(C.CRoot_Tree_GetTreeNumber(c_tree)),
+	}
+	return f, nil
+}
+
+func (f *formula_impl) Delete() {
+	C.CRoot_TreeFormula_delete(f.c)
+	f.c = nil
+}
+
+func (f *formula_impl) GetExpFormula() string {
+	return f.expr
+}
+
+// GetNdata returns the number of values the formula yields for the
+// current entry (more than one for array expressions.)
+func (f *formula_impl) GetNdata() int {
+	// a Chain may have moved on to a new Tree: re-bind the leaves.
+	if n := int(C.CRoot_Tree_GetTreeNumber(f.tree)); n != f.treenum {
+		C.CRoot_TreeFormula_UpdateFormulaLeaves(f.c)
+		f.treenum = n
+	}
+	return int(C.CRoot_TreeFormula_GetNdata(f.c))
+}
+
+func (f *formula_impl) EvalInstance(i int) float64 {
+	return float64(C.CRoot_TreeFormula_EvalInstance(f.c, C.int32_t(i)))
+}
+
+// GetLeaves returns the leaves the formula depends on.
+func (f *formula_impl) GetLeaves() []Leaf {
+	n := int(C.CRoot_TreeFormula_GetNcodes(f.c))
+	leaves := make([]Leaf, 0, n)
+	for i := 0; i < n; i++ {
+		c := C.CRoot_TreeFormula_GetLeaf(f.c, C.int32_t(i))
+		if c == nil {
+			continue
+		}
+		leaves = append(leaves, &leaf_impl{c: c})
+	}
+	return leaves
+}
+
+// EOF
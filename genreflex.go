@@ -72,7 +72,8 @@ func init() {
 }
 
 // RegisterType declares the (equivalent) C-layout of value v to ROOT so
-// values of the same type than v can be written out to ROOT files
+// values of the same type than v can be written out to ROOT files.
+// Registered types can be declared to C++ with GenCxxDict.
 func RegisterType(v interface{}) {
 	rv := reflect.ValueOf(v)
 	if rv.Kind() == reflect.Ptr {
@@ -81,6 +82,12 @@ func RegisterType(v interface{}) {
 	t := rv.Type()
 	//fmt.Printf("registering [%s] (sz:%d)...\n",t, t.Size())
 	genreflex(t)
+	for _, rt := range registered_types {
+		if rt == t {
+			return
+		}
+	}
+	registered_types = append(registered_types, t)
 }
 
 func follow_ptr(v reflect.Value) reflect.Value {
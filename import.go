@@ -0,0 +1,235 @@
+package croot
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ImportOptions customizes the import of CSV data into a Tree
+type ImportOptions struct {
+	// Types overrides the inferred kinds of columns, indexed by CSV header
+	// or branch name (any kind supported by Writer).
+	Types map[string]reflect.Kind
+
+	Sample  int  // number of records used to infer the types of the columns (default: 1000)
+	Comma   rune // field delimiter (default: ',')
+	BufSize int  // buffer size of the branches (default: 32000)
+}
+
+// ImportCSV reads CSV data with a header line from r and fills t with a
+// branch per column, returning the number of imported records.
+// Branches are named after the header, with characters not valid in
+// identifiers replaced by '_'.
+// The kinds of the columns are inferred from the first records (see
+// ImportOptions.Sample): int64 if all the values are integers, float64 if
+// they are numbers, string otherwise. Empty values are imported as 0 (or
+// NaN for floats) and "" for strings.
+// Strings longer than both 255 bytes and the longest sampled string of
+// their column are reported as errors.
+func ImportCSV(r io.Reader, t Tree, opts ImportOptions) (int64, error) {
+	if opts.Sample <= 0 {
+		opts.Sample = 1000
+	}
+	if opts.BufSize <= 0 {
+		opts.BufSize = 32000
+	}
+
+	cr := csv.NewReader(r)
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+
+	header, err := cr.Read()
+	if err != nil {
+		return 0, fmt.Errorf("croot.ImportCSV: could not read header: %v", err)
+	}
+	names := branch_names(header)
+
+	var sample [][]string
+	for len(sample) < opts.Sample {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("croot.ImportCSV: %v", err)
+		}
+		sample = append(sample, rec)
+	}
+
+	specs := make([]ColumnSpec, len(header))
+	for i := range header {
+		spec := ColumnSpec{Name: names[i], Kind: infer_kind(sample, i)}
+		if k, ok := opts.Types[header[i]]; ok {
+			spec.Kind = k
+		} else if k, ok := opts.Types[names[i]]; ok {
+			spec.Kind = k
+		}
+		if spec.Kind == reflect.String {
+			spec.Len = default_string_len
+			for _, rec := range sample {
+				if n := len(rec[i]); n > spec.Len {
+					spec.Len = n
+				}
+			}
+		}
+		specs[i] = spec
+	}
+	for name := range opts.Types {
+		found := false
+		for i := range header {
+			found = found || name == header[i] || name == names[i]
+		}
+		if !found {
+			return 0, fmt.Errorf("croot.ImportCSV: no column [%s]", name)
+		}
+	}
+
+	w, err := NewWriter(t, specs, opts.BufSize)
+	if err != nil {
+		return 0, err
+	}
+
+	n := int64(0)
+	row := make([]interface{}, len(specs))
+	write := func(rec []string) error {
+		for i, spec := range specs {
+			v, err := parse_field(spec, rec[i])
+			if err != nil {
+				return fmt.Errorf("croot.ImportCSV: record %d: %v", n+1, err)
+			}
+			row[i] = v
+		}
+		_, err := w.Write(row)
+		if err != nil {
+			return fmt.Errorf("croot.ImportCSV: record %d: %v", n+1, err)
+		}
+		n++
+		return nil
+	}
+
+	for _, rec := range sample {
+		err = write(rec)
+		if err != nil {
+			return n, err
+		}
+	}
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return n, fmt.Errorf("croot.ImportCSV: %v", err)
+		}
+		err = write(rec)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// branch_names returns valid and unique branch names for the columns of a
+// CSV header.
+func branch_names(header []string) []string {
+	names := make([]string, len(header))
+	used := make(map[string]int, len(header))
+	for i, h := range header {
+		name := strings.Map(func(r rune) rune {
+			if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+			return '_'
+		}, strings.TrimSpace(h))
+		switch {
+		case name == "":
+			name = fmt.Sprintf("col%d", i)
+		case unicode.IsDigit([]rune(name)[0]):
+			name = "_" + name
+		}
+		// "name_N" may be a column of its own: loop until unused.
+		id := name
+		for used[id] > 0 {
+			id = fmt.Sprintf("%s_%d", name, used[name])
+			used[name]++
+		}
+		used[id]++
+		names[i] = id
+	}
+	return names
+}
+
+// infer_kind returns the kind of the i-th column of the sampled records.
+func infer_kind(sample [][]string, i int) reflect.Kind {
+	kind := reflect.Int64
+	for _, rec := range sample {
+		v := strings.TrimSpace(rec[i])
+		if v == "" {
+			continue
+		}
+		if kind == reflect.Int64 {
+			if _, err := strconv.ParseInt(v, 10, 64); err == nil {
+				continue
+			}
+			kind = reflect.Float64
+		}
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			return reflect.String
+		}
+	}
+	return kind
+}
+
+// parse_field converts a CSV field to a value of the kind of its column.
+func parse_field(spec ColumnSpec, field string) (interface{}, error) {
+	if spec.Kind == reflect.String {
+		return field, nil
+	}
+	v := strings.TrimSpace(field)
+	switch spec.Kind {
+	case reflect.Bool:
+		if v == "" {
+			return false, nil
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("column [%s]: invalid bool [%s]", spec.Name, field)
+		}
+		return b, nil
+	case reflect.Float32, reflect.Float64:
+		if v == "" {
+			return math.NaN(), nil
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("column [%s]: invalid number [%s]", spec.Name, field)
+		}
+		return f, nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v == "" {
+			return uint64(0), nil
+		}
+		u, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("column [%s]: invalid unsigned integer [%s]", spec.Name, field)
+		}
+		return u, nil
+	}
+	if v == "" {
+		return int64(0), nil
+	}
+	i, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("column [%s]: invalid integer [%s]", spec.Name, field)
+	}
+	return i, nil
+}
+
+// EOF
@@ -12,6 +12,7 @@ import (
 type Leaf interface {
 	Object
 	GetBranch() Branch
+	GetLen() int
 	GetLenStatic() int
 	GetLeafCount() Leaf
 	GetTypeName() string
@@ -67,14 +68,27 @@ func (l *leaf_impl) Print(option Option) {
 	l.as_tobject().Print(option)
 }
 
+// GetLen returns the number of values of the leaf for the current entry.
+func (l *leaf_impl) GetLen() int {
+	return int(C.CRoot_Leaf_GetLen(l.c))
+}
+
 func (l *leaf_impl) GetLenStatic() int {
 	return int(C.CRoot_Leaf_GetLenStatic(l.c))
 }
 
 func (l *leaf_impl) GetLeafCount() Leaf {
 	c := C.CRoot_Leaf_GetLeafCount(l.c)
-	obj := object_impl{c: (C.CRoot_Object)(c)}
-	return to_gocroot(&obj).(Leaf)
+	if c == nil {
+		return nil
+	}
+	obj := &object_impl{c: (C.CRoot_Object)(c)}
+	if _, ok := cnvmap[obj.ClassName()]; ok {
+		if leaf, ok := to_gocroot(obj).(Leaf); ok {
+			return leaf
+		}
+	}
+	return &leaf_impl{c: c}
 }
 
 func (l *leaf_impl) GetTypeName() string {
@@ -144,6 +158,10 @@ func (l *leaf_i_impl) Print(option Option) {
 	l.as_tobject().Print(option)
 }
 
+func (l *leaf_i_impl) GetLen() int {
+	return int(C.CRoot_Leaf_GetLen(l.as_tleaf()))
+}
+
 func (l *leaf_i_impl) GetLenStatic() int {
 	return int(C.CRoot_Leaf_GetLenStatic(l.as_tleaf()))
 }
@@ -221,6 +239,10 @@ func (l *leaf_f_impl) Print(option Option) {
 	l.as_tobject().Print(option)
 }
 
+func (l *leaf_f_impl) GetLen() int {
+	return int(C.CRoot_Leaf_GetLen(l.as_tleaf()))
+}
+
 func (l *leaf_f_impl) GetLenStatic() int {
 	return int(C.CRoot_Leaf_GetLenStatic(l.as_tleaf()))
 }
@@ -298,6 +320,10 @@ func (l *leaf_d_impl) Print(option Option) {
 	l.as_tobject().Print(option)
 }
 
+func (l *leaf_d_impl) GetLen() int {
+	return int(C.CRoot_Leaf_GetLen(l.as_tleaf()))
+}
+
 func (l *leaf_d_impl) GetLenStatic() int {
 	return int(C.CRoot_Leaf_GetLenStatic(l.as_tleaf()))
 }
@@ -375,6 +401,10 @@ func (l *leaf_o_impl) Print(option Option) {
 	l.as_tobject().Print(option)
 }
 
+func (l *leaf_o_impl) GetLen() int {
+	return int(C.CRoot_Leaf_GetLen(l.as_tleaf()))
+}
+
 func (l *leaf_o_impl) GetLenStatic() int {
 	return int(C.CRoot_Leaf_GetLenStatic(l.as_tleaf()))
 }
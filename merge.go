@@ -0,0 +1,219 @@
+package croot
+
+// #include "croot/croot.h"
+//
+// #include <stdlib.h>
+// #include <string.h>
+import "C"
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+// MergeOptions customizes the merging of ROOT files
+type MergeOptions struct {
+	Compress int      // compression level of the output file (0: no compression)
+	Keys     []string // names of the top-level keys to merge (all of them if empty)
+	Parallel int      // number of concurrent partial merges (sequential merge if <= 1)
+	Force    bool     // overwrite the output file if it already exists
+	Verbose  bool     // print the progress of the merge
+}
+
+// DefaultMergeOptions are the options used by MergeFiles
+var DefaultMergeOptions = MergeOptions{Compress: 1}
+
+// MergeFiles merges the content of the inputs ROOT files into out:
+// trees are merged by name and histograms are added.
+// It is the equivalent of ROOT's hadd.
+func MergeFiles(out string, inputs ...string) error {
+	return MergeFilesWith(out, DefaultMergeOptions, inputs...)
+}
+
+// MergeFilesWith merges the content of the inputs ROOT files into out,
+// according to opts.
+// An error is returned if keys with the same name hold objects of
+// different types in different inputs.
+func MergeFilesWith(out string, opts MergeOptions, inputs ...string) error {
+	if len(inputs) == 0 {
+		return fmt.Errorf("croot.MergeFiles: no input file")
+	}
+	if !opts.Force {
+		if _, err := os.Stat(out); err == nil {
+			return fmt.Errorf("croot.MergeFiles: output file [%s] already exists", out)
+		}
+	}
+
+	err := check_merge_types(opts.Keys, inputs)
+	if err != nil {
+		return err
+	}
+
+	if opts.Parallel <= 1 || len(inputs) <= opts.Parallel {
+		return merge_files(out, opts, inputs)
+	}
+
+	tmpdir, err := ioutil.TempDir("", "croot-merge-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpdir)
+
+	enable_thread_safety.Do(EnableThreadSafety)
+
+	// merge chunks of the inputs concurrently, then merge the partial results.
+	// there may be less chunks than workers (e.g. 5 inputs on 4 workers
+	// make 3 chunks of 2 inputs).
+	chunk := (len(inputs) + opts.Parallel - 1) / opts.Parallel
+	nchunks := (len(inputs) + chunk - 1) / chunk
+	partials := make([]string, nchunks)
+	errs := make([]error, nchunks)
+	var wg sync.WaitGroup
+	for i := range partials {
+		beg := i * chunk
+		end := beg + chunk
+		if end > len(inputs) {
+			end = len(inputs)
+		}
+		partials[i] = filepath.Join(tmpdir, fmt.Sprintf("partial-%03d.root", i))
+		wg.Add(1)
+		go func(i int, inputs []string) {
+			defer wg.Done()
+			// ROOT keeps per-thread state (current directory, ...)
+			runtime.LockOSThread()
+			defer runtime.UnlockOSThread()
+			popts := opts
+			popts.Force = true
+			errs[i] = merge_files(partials[i], popts, inputs)
+		}(i, inputs[beg:end])
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return merge_files(out, opts, partials)
+}
+
+var enable_thread_safety sync.Once
+
+// check_merge_types makes sure the keys (of the given names, or all of
+// them if names is empty) hold objects of the same type in all inputs.
+// Keys of sub-directories are checked as well, by path (e.g. "dir/h").
+func check_merge_types(names []string, inputs []string) error {
+	sel := make(map[string]bool, len(names))
+	for _, n := range names {
+		sel[n] = true
+	}
+
+	types := make(map[string]merge_origin)
+	for _, fname := range inputs {
+		f, err := OpenFile(fname, "read", "croot-merge-input", 1, 0)
+		if err != nil {
+			return fmt.Errorf("croot.MergeFiles: could not open input file [%s]", fname)
+		}
+		err = check_merge_dir(types, sel, f, "", fname)
+		f.Close("")
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, n := range names {
+		if _, ok := types[n]; !ok {
+			return fmt.Errorf("croot.MergeFiles: no key [%s] in input files", n)
+		}
+	}
+	return nil
+}
+
+type merge_origin struct {
+	class string
+	fname string
+}
+
+// check_merge_dir records the types of the keys of dir (from file fname)
+// into types, and recurses into sub-directories.
+// Only the top-level keys are filtered by sel.
+func check_merge_dir(types map[string]merge_origin, sel map[string]bool, dir interface {
+	Get(namecycle string) Object
+	GetListOfKeys() []Key
+}, path, fname string) error {
+	for _, k := range dir.GetListOfKeys() {
+		if path == "" && len(sel) > 0 && !sel[k.Name] {
+			continue
+		}
+		name := k.Name
+		if path != "" {
+			name = path + "/" + k.Name
+		}
+		o, dup := types[name]
+		if !dup {
+			types[name] = merge_origin{k.ClassName, fname}
+		} else if o.class != k.ClassName {
+			return fmt.Errorf(
+				"croot.MergeFiles: type mismatch for key [%s]: [%s] in [%s], [%s] in [%s]",
+				name, o.class, o.fname, k.ClassName, fname,
+			)
+		}
+		if !strings.HasPrefix(k.ClassName, "TDirectory") {
+			continue
+		}
+		if sub, ok := dir.Get(k.Name).(Directory); ok {
+			err := check_merge_dir(types, sel, sub, name, fname)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func merge_files(out string, opts MergeOptions, inputs []string) error {
+	m := C.CRoot_FileMerger_new(bool2c(false))
+	defer C.CRoot_FileMerger_delete(m)
+
+	if opts.Verbose {
+		C.CRoot_FileMerger_SetPrintLevel(m, 1)
+	} else {
+		C.CRoot_FileMerger_SetPrintLevel(m, 0)
+	}
+
+	c_out := C.CString(out)
+	defer C.free(unsafe.Pointer(c_out))
+	if !c2bool(C.CRoot_FileMerger_OutputFile(m, c_out, bool2c(opts.Force), C.int32_t(opts.Compress))) {
+		return fmt.Errorf("croot.MergeFiles: could not create output file [%s]", out)
+	}
+
+	for _, fname := range inputs {
+		c_fname := C.CString(fname)
+		ok := c2bool(C.CRoot_FileMerger_AddFile(m, c_fname))
+		C.free(unsafe.Pointer(c_fname))
+		if !ok {
+			return fmt.Errorf("croot.MergeFiles: could not add input file [%s]", fname)
+		}
+	}
+
+	if len(opts.Keys) > 0 {
+		c_names := C.CString(strings.Join(opts.Keys, " "))
+		defer C.free(unsafe.Pointer(c_names))
+		if !c2bool(C.CRoot_FileMerger_AddObjectNames(m, c_names)) {
+			return fmt.Errorf("croot.MergeFiles: selecting keys is not supported by this version of ROOT")
+		}
+	}
+
+	if !c2bool(C.CRoot_FileMerger_Merge(m)) {
+		return fmt.Errorf("croot.MergeFiles: could not merge files into [%s]", out)
+	}
+	return nil
+}
+
+// EOF
@@ -0,0 +1,361 @@
+package croot
+
+/*
+ #include "croot/croot.h"
+
+ #include <stdlib.h>
+ #include <string.h>
+
+ extern double GoCRoot_Minimizer_Fcn(int64_t ctx, int32_t npar, double *x);
+
+ static
+ double
+ _go_croot_minimizer_fcn_stub(int64_t ctx, int32_t npar, double *x)
+ {
+   return GoCRoot_Minimizer_Fcn(ctx, npar, x);
+ }
+
+ static
+ CRoot_Minimizer_Fcn
+ _get_go_croot_minimizer_fcn_stub() { return &_go_croot_minimizer_fcn_stub; }
+*/
+import "C"
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"unsafe"
+)
+
+// Minimizer wraps a ROOT::Math::Minimizer (Minuit, Minuit2, ...) driving
+// the minimization of a Go objective function.
+type Minimizer interface {
+	// SetFunction sets the objective function of npar parameters.
+	SetFunction(npar int, fct func(params []float64) float64)
+
+	SetVariable(ivar int, name string, val, step float64) error
+	SetLimitedVariable(ivar int, name string, val, step, lower, upper float64) error
+	SetLowerLimitedVariable(ivar int, name string, val, step, lower float64) error
+	SetUpperLimitedVariable(ivar int, name string, val, step, upper float64) error
+	SetFixedVariable(ivar int, name string, val float64) error
+	FixVariable(ivar int) error
+	ReleaseVariable(ivar int) error
+	IsFixedVariable(ivar int) bool
+
+	SetPrintLevel(lvl int)
+	SetStrategy(strategy int)
+	SetTolerance(tol float64)
+	SetErrorDef(up float64)
+	SetMaxFunctionCalls(n int)
+	SetMaxIterations(n int)
+
+	// Minimize runs the minimization algorithm (e.g. MIGRAD)
+	Minimize() error
+	// Hesse computes the full error matrix with HESSE
+	Hesse() error
+	// Minos computes the asymmetric MINOS errors of parameter ivar
+	Minos(ivar int) (errlow, errup float64, err error)
+
+	Status() int
+	CovMatrixStatus() int
+	MinValue() float64
+	Edm() float64
+	NCalls() int
+	NDim() int
+	X() []float64
+	Errors() []float64
+	CovMatrix(i, j int) float64
+
+	Delete()
+}
+
+type minimizer_impl struct {
+	c    C.CRoot_Minimizer
+	id   int64
+	fct  func(params []float64) float64
+	pars []float64
+	err  error // first panic of the objective function, since the last run
+}
+
+// registry of live minimizers, indexed by the opaque handle handed to C.
+// Go pointers can not be stored on the C side.
+var minimizers = struct {
+	sync.RWMutex
+	id  int64
+	ids map[int64]*minimizer_impl
+}{ids: make(map[int64]*minimizer_impl)}
+
+// GoCRoot_Minimizer_Fcn is called from the C++ minimizer: panics can not
+// unwind through its frames, so they are recovered, recorded on the
+// minimizer (and reported by Minimize, Hesse or Minos) and NaN is returned.
+//
+//export GoCRoot_Minimizer_Fcn
+func GoCRoot_Minimizer_Fcn(ctx C.int64_t, npar C.int32_t, x *C.double) (val C.double) {
+	minimizers.RLock()
+	m := minimizers.ids[int64(ctx)]
+	minimizers.RUnlock()
+	if m == nil || m.fct == nil {
+		return C.double(math.NaN())
+	}
+	if m.err != nil {
+		return C.double(math.NaN())
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			m.err = fmt.Errorf("objective function panicked: %v", r)
+			val = C.double(math.NaN())
+		}
+	}()
+
+	n := int(npar)
+	xs := (*[1 << 30]C.double)(unsafe.Pointer(x))[:n:n]
+	for i, v := range xs {
+		m.pars[i] = float64(v)
+	}
+	return C.double(m.fct(m.pars))
+}
+
+// fcn_error returns (and clears) the error recorded by the objective
+// function during the last run.
+func (m *minimizer_impl) fcn_error() error {
+	err := m.err
+	m.err = nil
+	return err
+}
+
+// NewMinimizer creates a new minimizer of the given type ("Minuit",
+// "Minuit2", "GSLMultiMin", ...) using the given algorithm ("Migrad",
+// "Simplex", "Combined", ...)
+func NewMinimizer(typ, algo string) (Minimizer, error) {
+	c_typ := C.CString(typ)
+	defer C.free(unsafe.Pointer(c_typ))
+	c_algo := C.CString(algo)
+	defer C.free(unsafe.Pointer(c_algo))
+
+	c := C.CRoot_Minimizer_new(c_typ, c_algo)
+	if c == nil {
+		return nil, fmt.Errorf("croot.NewMinimizer: could not create minimizer [%s/%s]", typ, algo)
+	}
+
+	minimizers.Lock()
+	minimizers.id++
+	m := &minimizer_impl{c: c, id: minimizers.id}
+	minimizers.ids[m.id] = m
+	minimizers.Unlock()
+	return m, nil
+}
+
+func (m *minimizer_impl) Delete() {
+	minimizers.Lock()
+	delete(minimizers.ids, m.id)
+	minimizers.Unlock()
+	C.CRoot_Minimizer_delete(m.c)
+	m.c = nil
+	m.fct = nil
+}
+
+func (m *minimizer_impl) SetFunction(npar int, fct func(params []float64) float64) {
+	m.fct = fct
+	m.pars = make([]float64, npar)
+	C.CRoot_Minimizer_SetFunction(
+		m.c,
+		C._get_go_croot_minimizer_fcn_stub(),
+		C.int64_t(m.id),
+		C.int32_t(npar),
+	)
+}
+
+func (m *minimizer_impl) SetVariable(ivar int, name string, val, step float64) error {
+	c_name := C.CString(name)
+	defer C.free(unsafe.Pointer(c_name))
+	o := C.CRoot_Minimizer_SetVariable(m.c, C.int32_t(ivar), c_name, C.double(val), C.double(step))
+	if !c2bool(o) {
+		return fmt.Errorf("croot.Minimizer.SetVariable: could not set variable [%d] (%s)", ivar, name)
+	}
+	return nil
+}
+
+func (m *minimizer_impl) SetLimitedVariable(ivar int, name string, val, step, lower, upper float64) error {
+	c_name := C.CString(name)
+	defer C.free(unsafe.Pointer(c_name))
+	o := C.CRoot_Minimizer_SetLimitedVariable(
+		m.c, C.int32_t(ivar), c_name,
+		C.double(val), C.double(step),
+		C.double(lower), C.double(upper),
+	)
+	if !c2bool(o) {
+		return fmt.Errorf("croot.Minimizer.SetLimitedVariable: could not set variable [%d] (%s)", ivar, name)
+	}
+	return nil
+}
+
+func (m *minimizer_impl) SetLowerLimitedVariable(ivar int, name string, val, step, lower float64) error {
+	c_name := C.CString(name)
+	defer C.free(unsafe.Pointer(c_name))
+	o := C.CRoot_Minimizer_SetLowerLimitedVariable(
+		m.c, C.int32_t(ivar), c_name,
+		C.double(val), C.double(step),
+		C.double(lower),
+	)
+	if !c2bool(o) {
+		return fmt.Errorf("croot.Minimizer.SetLowerLimitedVariable: could not set variable [%d] (%s)", ivar, name)
+	}
+	return nil
+}
+
+func (m *minimizer_impl) SetUpperLimitedVariable(ivar int, name string, val, step, upper float64) error {
+	c_name := C.CString(name)
+	defer C.free(unsafe.Pointer(c_name))
+	o := C.CRoot_Minimizer_SetUpperLimitedVariable(
+		m.c, C.int32_t(ivar), c_name,
+		C.double(val), C.double(step),
+		C.double(upper),
+	)
+	if !c2bool(o) {
+		return fmt.Errorf("croot.Minimizer.SetUpperLimitedVariable: could not set variable [%d] (%s)", ivar, name)
+	}
+	return nil
+}
+
+func (m *minimizer_impl) SetFixedVariable(ivar int, name string, val float64) error {
+	c_name := C.CString(name)
+	defer C.free(unsafe.Pointer(c_name))
+	o := C.CRoot_Minimizer_SetFixedVariable(m.c, C.int32_t(ivar), c_name, C.double(val))
+	if !c2bool(o) {
+		return fmt.Errorf("croot.Minimizer.SetFixedVariable: could not set variable [%d] (%s)", ivar, name)
+	}
+	return nil
+}
+
+func (m *minimizer_impl) FixVariable(ivar int) error {
+	if !c2bool(C.CRoot_Minimizer_FixVariable(m.c, C.int32_t(ivar))) {
+		return fmt.Errorf("croot.Minimizer.FixVariable: could not fix variable [%d]", ivar)
+	}
+	return nil
+}
+
+func (m *minimizer_impl) ReleaseVariable(ivar int) error {
+	if !c2bool(C.CRoot_Minimizer_ReleaseVariable(m.c, C.int32_t(ivar))) {
+		return fmt.Errorf("croot.Minimizer.ReleaseVariable: could not release variable [%d]", ivar)
+	}
+	return nil
+}
+
+func (m *minimizer_impl) IsFixedVariable(ivar int) bool {
+	return c2bool(C.CRoot_Minimizer_IsFixedVariable(m.c, C.int32_t(ivar)))
+}
+
+func (m *minimizer_impl) SetPrintLevel(lvl int) {
+	C.CRoot_Minimizer_SetPrintLevel(m.c, C.int32_t(lvl))
+}
+
+func (m *minimizer_impl) SetStrategy(strategy int) {
+	C.CRoot_Minimizer_SetStrategy(m.c, C.int32_t(strategy))
+}
+
+func (m *minimizer_impl) SetTolerance(tol float64) {
+	C.CRoot_Minimizer_SetTolerance(m.c, C.double(tol))
+}
+
+func (m *minimizer_impl) SetErrorDef(up float64) {
+	C.CRoot_Minimizer_SetErrorDef(m.c, C.double(up))
+}
+
+func (m *minimizer_impl) SetMaxFunctionCalls(n int) {
+	C.CRoot_Minimizer_SetMaxFunctionCalls(m.c, C.uint32_t(n))
+}
+
+func (m *minimizer_impl) SetMaxIterations(n int) {
+	C.CRoot_Minimizer_SetMaxIterations(m.c, C.uint32_t(n))
+}
+
+func (m *minimizer_impl) Minimize() error {
+	if m.fct == nil {
+		return fmt.Errorf("croot.Minimizer.Minimize: no objective function set")
+	}
+	m.err = nil
+	ok := c2bool(C.CRoot_Minimizer_Minimize(m.c))
+	if err := m.fcn_error(); err != nil {
+		return fmt.Errorf("croot.Minimizer.Minimize: %v", err)
+	}
+	if !ok {
+		return fmt.Errorf("croot.Minimizer.Minimize: minimization failed (status=%d)", m.Status())
+	}
+	return nil
+}
+
+func (m *minimizer_impl) Hesse() error {
+	m.err = nil
+	ok := c2bool(C.CRoot_Minimizer_Hesse(m.c))
+	if err := m.fcn_error(); err != nil {
+		return fmt.Errorf("croot.Minimizer.Hesse: %v", err)
+	}
+	if !ok {
+		return fmt.Errorf("croot.Minimizer.Hesse: error matrix computation failed (status=%d)", m.Status())
+	}
+	return nil
+}
+
+func (m *minimizer_impl) Minos(ivar int) (errlow, errup float64, err error) {
+	c_low := (*C.double)(unsafe.Pointer(&errlow))
+	c_up := (*C.double)(unsafe.Pointer(&errup))
+	m.err = nil
+	ok := c2bool(C.CRoot_Minimizer_GetMinosError(m.c, C.int32_t(ivar), c_low, c_up))
+	if fcn_err := m.fcn_error(); fcn_err != nil {
+		return errlow, errup, fmt.Errorf("croot.Minimizer.Minos: %v", fcn_err)
+	}
+	if !ok {
+		err = fmt.Errorf("croot.Minimizer.Minos: MINOS failed for variable [%d] (status=%d)", ivar, m.Status())
+	}
+	return
+}
+
+func (m *minimizer_impl) Status() int {
+	return int(C.CRoot_Minimizer_Status(m.c))
+}
+
+func (m *minimizer_impl) CovMatrixStatus() int {
+	return int(C.CRoot_Minimizer_CovMatrixStatus(m.c))
+}
+
+func (m *minimizer_impl) MinValue() float64 {
+	return float64(C.CRoot_Minimizer_MinValue(m.c))
+}
+
+func (m *minimizer_impl) Edm() float64 {
+	return float64(C.CRoot_Minimizer_Edm(m.c))
+}
+
+func (m *minimizer_impl) NCalls() int {
+	return int(C.CRoot_Minimizer_NCalls(m.c))
+}
+
+func (m *minimizer_impl) NDim() int {
+	return int(C.CRoot_Minimizer_NDim(m.c))
+}
+
+func (m *minimizer_impl) X() []float64 {
+	x := make([]float64, m.NDim())
+	if len(x) == 0 {
+		return x
+	}
+	C.CRoot_Minimizer_X(m.c, (*C.double)(unsafe.Pointer(&x[0])))
+	return x
+}
+
+func (m *minimizer_impl) Errors() []float64 {
+	errs := make([]float64, m.NDim())
+	if len(errs) == 0 {
+		return errs
+	}
+	C.CRoot_Minimizer_Errors(m.c, (*C.double)(unsafe.Pointer(&errs[0])))
+	return errs
+}
+
+func (m *minimizer_impl) CovMatrix(i, j int) float64 {
+	return float64(C.CRoot_Minimizer_CovMatrix(m.c, C.int32_t(i), C.int32_t(j)))
+}
+
+// EOF
@@ -0,0 +1,282 @@
+package croot
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// npy_descrs maps the Go kinds to the NumPy array-protocol type strings
+var npy_descrs = map[reflect.Kind]string{
+	reflect.Bool:    "|b1",
+	reflect.Int8:    "|i1",
+	reflect.Uint8:   "|u1",
+	reflect.Int16:   "<i2",
+	reflect.Uint16:  "<u2",
+	reflect.Int32:   "<i4",
+	reflect.Uint32:  "<u4",
+	reflect.Int64:   "<i8",
+	reflect.Uint64:  "<u8",
+	reflect.Float32: "<f4",
+	reflect.Float64: "<f8",
+}
+
+// ExportNPY writes the numeric columns of t as NumPy .npy files in dir (a
+// file per array, created along with dir if needed) and returns the number
+// of exported entries.
+// Scalar columns are written as 1D arrays and fixed-size arrays as 2D
+// arrays (entries x elements). Variable-size arrays and std::vectors are
+// written as a pair of arrays: "name.offsets" (entries+1 int64 offsets) and
+// "name.values" (the concatenated elements), the values of entry i being
+// values[offsets[i]:offsets[i+1]].
+// Non-numeric columns are reported as errors if explicitly selected,
+// skipped otherwise. opts.Explode is ignored.
+func ExportNPY(dir string, t Tree, opts ExportOptions) (int64, error) {
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return 0, err
+	}
+	return export_npy(t, opts, func(name string, arr *npy_array) error {
+		f, err := os.Create(filepath.Join(dir, name+".npy"))
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		err = arr.write_to(f)
+		if err != nil {
+			return err
+		}
+		return f.Close()
+	})
+}
+
+// ExportNPZ writes the numeric columns of t as a NumPy .npz archive (as
+// created by numpy.savez) into w and returns the number of exported
+// entries.
+// The arrays are laid out as described in ExportNPY.
+func ExportNPZ(w io.Writer, t Tree, opts ExportOptions) (int64, error) {
+	zw := zip.NewWriter(w)
+	n, err := export_npy(t, opts, func(name string, arr *npy_array) error {
+		o, err := zw.CreateHeader(&zip.FileHeader{
+			Name:   name + ".npy",
+			Method: zip.Store,
+		})
+		if err != nil {
+			return err
+		}
+		return arr.write_to(o)
+	})
+	if err != nil {
+		return n, err
+	}
+	return n, zw.Close()
+}
+
+// npy_column holds the arrays a column is written to
+type npy_column struct {
+	name    string
+	values  *npy_array
+	offsets *npy_array // offsets of the values of variable-size arrays
+	offset  int64
+}
+
+// export_npy stores the selected entries of t into temporary arrays, then
+// hands the complete arrays to save.
+func export_npy(t Tree, opts ExportOptions, save func(name string, arr *npy_array) error) (int64, error) {
+	var cols []*npy_column
+	defer func() {
+		for _, col := range cols {
+			if col == nil {
+				continue
+			}
+			col.values.close()
+			if col.offsets != nil {
+				col.offsets.close()
+			}
+		}
+	}()
+
+	n, err := export_entries(t, opts, func(r *Reader) error {
+		for _, c := range r.Columns() {
+			col, err := new_npy_column(c)
+			if err != nil {
+				if len(opts.Branches) > 0 {
+					return err
+				}
+				col = nil
+			}
+			cols = append(cols, col)
+		}
+		return nil
+	}, func(vals []interface{}) error {
+		for i, v := range vals {
+			col := cols[i]
+			if col == nil {
+				continue
+			}
+			err := col.values.append(v)
+			if err != nil {
+				return err
+			}
+			if col.offsets != nil {
+				col.offset += int64(reflect.ValueOf(v).Len())
+				err = col.offsets.append(col.offset)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return n, err
+	}
+
+	for _, col := range cols {
+		if col == nil {
+			continue
+		}
+		if col.offsets == nil {
+			err = save(col.name, col.values)
+			if err != nil {
+				return n, err
+			}
+			continue
+		}
+		err = save(col.name+".offsets", col.offsets)
+		if err != nil {
+			return n, err
+		}
+		err = save(col.name+".values", col.values)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func new_npy_column(c Column) (*npy_column, error) {
+	col := &npy_column{name: c.Name}
+	var err error
+	switch c.Type.Kind() {
+	case reflect.Array:
+		col.values, err = new_npy_array(c.Type.Elem(), c.Type.Len())
+	case reflect.Slice:
+		col.values, err = new_npy_array(c.Type.Elem(), -1)
+		if err == nil {
+			col.offsets, err = new_npy_array(reflect.TypeOf(int64(0)), 0)
+			if err == nil {
+				err = col.offsets.append(int64(0))
+			}
+		}
+	default:
+		col.values, err = new_npy_array(c.Type, 0)
+	}
+	if err != nil {
+		if col.values != nil {
+			col.values.close()
+		}
+		return nil, fmt.Errorf("croot.ExportNPY: column [%s]: %v", c.Name, err)
+	}
+	return col, nil
+}
+
+// npy_array is a NumPy array of unknown length, whose data is stored in
+// a temporary file until it is complete
+type npy_array struct {
+	descr string
+	dim   int   // number of elements per entry (0: scalars, -1: flat array)
+	len   int64 // number of appended elements
+	f     *os.File
+	w     *bufio.Writer
+}
+
+func new_npy_array(elem reflect.Type, dim int) (*npy_array, error) {
+	descr, ok := npy_descrs[elem.Kind()]
+	if !ok {
+		return nil, fmt.Errorf("unsupported type [%v]", elem)
+	}
+	f, err := ioutil.TempFile("", "croot-npy-")
+	if err != nil {
+		return nil, err
+	}
+	return &npy_array{descr: descr, dim: dim, f: f, w: bufio.NewWriter(f)}, nil
+}
+
+// append appends a value (an element, an array of elements or a slice of
+// elements) to the array.
+func (arr *npy_array) append(v interface{}) error {
+	switch arr.dim {
+	case -1:
+		arr.len += int64(reflect.ValueOf(v).Len())
+	default:
+		arr.len++
+	}
+	return binary.Write(arr.w, binary.LittleEndian, v)
+}
+
+// shape returns the shape of the complete array.
+func (arr *npy_array) shape() []int64 {
+	if arr.dim > 0 {
+		return []int64{arr.len, int64(arr.dim)}
+	}
+	return []int64{arr.len}
+}
+
+// write_to writes the array in the .npy format into w.
+func (arr *npy_array) write_to(w io.Writer) error {
+	err := arr.w.Flush()
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(npy_header(arr.descr, arr.shape()))
+	if err != nil {
+		return err
+	}
+	_, err = arr.f.Seek(0, io.SeekStart)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, arr.f)
+	return err
+}
+
+func (arr *npy_array) close() {
+	arr.f.Close()
+	os.Remove(arr.f.Name())
+}
+
+// npy_header returns the header of a (version 1.0) .npy file, padded so
+// the data is 64-bytes aligned.
+func npy_header(descr string, shape []int64) []byte {
+	dims := make([]string, len(shape))
+	for i, d := range shape {
+		dims[i] = strconv.FormatInt(d, 10)
+	}
+	str := strings.Join(dims, ", ")
+	if len(shape) == 1 {
+		str += ","
+	}
+	dict := fmt.Sprintf("{'descr': '%s', 'fortran_order': False, 'shape': (%s), }", descr, str)
+
+	const prefix = 10 // magic string, version and header length
+	pad := (64 - (prefix+len(dict)+1)%64) % 64
+	hdr := make([]byte, 0, prefix+len(dict)+pad+1)
+	hdr = append(hdr, "\x93NUMPY\x01\x00"...)
+	hdr = append(hdr, 0, 0)
+	binary.LittleEndian.PutUint16(hdr[8:], uint16(len(dict)+pad+1))
+	hdr = append(hdr, dict...)
+	hdr = append(hdr, strings.Repeat(" ", pad)...)
+	hdr = append(hdr, '\n')
+	return hdr
+}
+
+// EOF
@@ -79,6 +79,16 @@ func (o *object_impl) Print(option Option) {
 	C.CRoot_Object_Print(o.c, (*C.CRoot_Option)(c_option))
 }
 
+func (o *object_impl) Write(name string, option, bufsize int) int {
+	if len(name) != 0 {
+		c_name := C.CString(name)
+		defer C.free(unsafe.Pointer(c_name))
+		return int(C.CRoot_Object_Write(o.c, c_name, C.int32_t(option), C.int32_t(bufsize)))
+	}
+	c_name := (*C.char)(unsafe.Pointer(nil))
+	return int(C.CRoot_Object_Write(o.c, c_name, C.int32_t(option), C.int32_t(bufsize)))
+}
+
 func init() {
 	cnvmap["TObject"] = func(o c_object) Object {
 		return &object_impl{c: (C.CRoot_Object)(o.cptr())}
@@ -5,16 +5,33 @@ package croot
 import "C"
 
 import (
+	"math/rand"
 	"unsafe"
 )
 
 // TRandom
+//
+// A Random is not safe for concurrent use: use NewRandom3 to create one
+// independent generator per goroutine.
 type Random interface {
 	Object
+	Delete()
+	SetSeed(seed uint32)
+	GetSeed() uint32
+
+	BreitWigner(mean, gamma float64) float64
+	Binomial(ntot int, prob float64) int
+	Exp(tau float64) float64
 	Gaus(mean, sigma float64) float64
+	Integer(imax uint32) uint32
+	Landau(mean, sigma float64) float64
+	Poisson(mean float64) int
 	Rannorf() (a, b float32)
 	Rannord() (a, b float64)
 	Rndm(i int) float64
+	RndmArray(n int) []float64
+	Sphere(r float64) (x, y, z float64)
+	Uniform(x1, x2 float64) float64
 }
 
 type random_impl struct {
@@ -59,11 +76,66 @@ func (r *random_impl) Print(option Option) {
 
 var GRandom Random = nil
 
+// NewRandom3 creates a new, independent, TRandom3 (Mersenne-Twister)
+// generator. A seed of 0 makes ROOT pick a unique seed.
+func NewRandom3(seed uint32) Random {
+	c := C.CRoot_Random3_new(C.uint32_t(seed))
+	return &random_impl{c: c}
+}
+
+// Delete deletes the generator.
+// It is a no-op for GRandom (ROOT's gRandom), which ROOT owns.
+func (r *random_impl) Delete() {
+	if r.c == nil || r.c == C.CRoot_gRandom {
+		return
+	}
+	C.CRoot_Random_delete(r.c)
+	r.c = nil
+}
+
+func (r *random_impl) SetSeed(seed uint32) {
+	C.CRoot_Random_SetSeed(r.c, C.uint32_t(seed))
+}
+
+func (r *random_impl) GetSeed() uint32 {
+	return uint32(C.CRoot_Random_GetSeed(r.c))
+}
+
+func (r *random_impl) BreitWigner(mean, gamma float64) float64 {
+	val := C.CRoot_Random_BreitWigner(r.c, C.double(mean), C.double(gamma))
+	return float64(val)
+}
+
+func (r *random_impl) Binomial(ntot int, prob float64) int {
+	val := C.CRoot_Random_Binomial(r.c, C.int32_t(ntot), C.double(prob))
+	return int(val)
+}
+
+func (r *random_impl) Exp(tau float64) float64 {
+	val := C.CRoot_Random_Exp(r.c, C.double(tau))
+	return float64(val)
+}
+
 func (r *random_impl) Gaus(mean, sigma float64) float64 {
 	val := C.CRoot_Random_Gaus(r.c, C.double(mean), C.double(sigma))
 	return float64(val)
 }
 
+func (r *random_impl) Integer(imax uint32) uint32 {
+	val := C.CRoot_Random_Integer(r.c, C.uint32_t(imax))
+	return uint32(val)
+}
+
+func (r *random_impl) Landau(mean, sigma float64) float64 {
+	val := C.CRoot_Random_Landau(r.c, C.double(mean), C.double(sigma))
+	return float64(val)
+}
+
+func (r *random_impl) Poisson(mean float64) int {
+	val := C.CRoot_Random_Poisson(r.c, C.double(mean))
+	return int(val)
+}
+
 func (r *random_impl) Rannorf() (a, b float32) {
 	c_a := (*C.float)(unsafe.Pointer(&a))
 	c_b := (*C.float)(unsafe.Pointer(&b))
@@ -83,11 +155,79 @@ func (r *random_impl) Rndm(i int) float64 {
 	return float64(val)
 }
 
+func (r *random_impl) RndmArray(n int) []float64 {
+	array := make([]float64, n)
+	if n <= 0 {
+		return array
+	}
+	C.CRoot_Random_RndmArray(r.c, C.int32_t(n), (*C.double)(unsafe.Pointer(&array[0])))
+	return array
+}
+
+func (r *random_impl) Sphere(radius float64) (x, y, z float64) {
+	c_x := (*C.double)(unsafe.Pointer(&x))
+	c_y := (*C.double)(unsafe.Pointer(&y))
+	c_z := (*C.double)(unsafe.Pointer(&z))
+	C.CRoot_Random_Sphere(r.c, c_x, c_y, c_z, C.double(radius))
+	return
+}
+
+func (r *random_impl) Uniform(x1, x2 float64) float64 {
+	val := C.CRoot_Random_Uniform(r.c, C.double(x1), C.double(x2))
+	return float64(val)
+}
+
+// randSource adapts a Random to the math/rand.Source64 interface
+type randSource struct {
+	r Random
+}
+
+// NewSource returns a math/rand.Source64 drawing its values from r, so a
+// *rand.Rand can be used with ROOT's generators.
+// Like r, the returned Source is not safe for concurrent use.
+func NewSource(r Random) rand.Source64 {
+	return &randSource{r: r}
+}
+
+// Seed seeds the generator with the 64 bits of seed, mixed (splitmix64)
+// and folded into a non-zero 32bit seed: ROOT picks a unique, thus
+// irreproducible, seed for 0.
+func (src *randSource) Seed(seed int64) {
+	z := uint64(seed) + 0x9e3779b97f4a7c15
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	z ^= z >> 31
+	u := uint32(z) ^ uint32(z>>32)
+	if u == 0 {
+		u = 0x9e3779b9
+	}
+	src.r.SetSeed(u)
+}
+
+// uint32 returns the 32 random bits of the next draw.
+// Rndm returns ]0,1[ with a 32bit resolution for TRandom3.
+func (src *randSource) uint32() uint32 {
+	return uint32(src.r.Rndm(0) * (1 << 32))
+}
+
+func (src *randSource) Uint64() uint64 {
+	hi := uint64(src.uint32())
+	lo := uint64(src.uint32())
+	return hi<<32 | lo
+}
+
+func (src *randSource) Int63() int64 {
+	return int64(src.Uint64() >> 1)
+}
+
 func init() {
 	GRandom = &random_impl{c: C.CRoot_gRandom}
 	cnvmap["TRandom"] = func(o c_object) Object {
 		return &random_impl{c: (C.CRoot_Random)(o.cptr())}
 	}
+	cnvmap["TRandom3"] = func(o c_object) Object {
+		return &random_impl{c: (C.CRoot_Random)(o.cptr())}
+	}
 }
 
 // EOF
@@ -0,0 +1,346 @@
+package croot
+
+// #include "croot/croot.h"
+//
+// #include <stdlib.h>
+// #include <string.h>
+import "C"
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"unsafe"
+)
+
+// Column describes a leaf of a Tree, as read by a Reader
+type Column struct {
+	Name   string       // name of the column ("branch" or "branch.leaf")
+	Branch string       // name of the branch holding the leaf
+	Type   reflect.Type // Go type of the values of the column
+	Leaf   Leaf
+
+	c      C.CRoot_Leaf
+	str    bool // a C-string (TLeafC)
+	fixed  int  // number of elements of fixed-size arrays
+	count  bool // a variable-size array
+	vector bool // a std::vector of builtins
+	stdstr bool // a std::string
+}
+
+// Reader reads the entries of a Tree without compile-time knowledge of
+// their Go type: the values are allocated and decoded from the leaves of
+// the Tree.
+// Builtins, fixed and variable-size arrays, C-strings, std::strings and
+// std::vectors of builtins are supported.
+//
+// Columns are flat: the leaves of a leaflist struct ("a/I:b/D") are read as
+// "branch.leaf" columns, the members of split objects as the columns of
+// their sub-branches, and multi-dimensional arrays ("m[2][3]/F") as
+// one-dimensional arrays ([6]float32).
+// Unsplit objects and std::vectors of other types are not supported.
+type Reader struct {
+	tree    Tree
+	cols    []Column
+	skipped []string
+	status  bool // branch statuses have to be restored
+	schema  *row_schema
+	entry   int64 // next entry read by Next
+}
+
+// NewReader creates a Reader for the given branches of t (all of them if
+// branches is empty).
+// A branch name selects all the leaves of the branch and of its
+// sub-branches. Leaves of types the Reader can not decode are reported as
+// errors if explicitly selected, skipped (see Skipped) otherwise.
+// Only the selected branches are read: the statuses of the branches of t
+// are restored by Close.
+func NewReader(t Tree, branches ...string) (*Reader, error) {
+	r := &Reader{tree: t}
+
+	tree := (C.CRoot_Tree)(unsafe.Pointer(t.(c_object).cptr()))
+	objs := objarray_impl{c: C.CRoot_Tree_GetListOfLeaves(tree)}
+	nleaves := objs.GetEntries()
+
+	used := make(map[string]bool, len(branches))
+	for i := int64(0); i < nleaves; i++ {
+		c := (C.CRoot_Leaf)(C.CRoot_ObjArray_At(objs.c, C.int64_t(i)))
+		leaf := &leaf_impl{c: c}
+		br := leaf.GetBranch().(*branch_impl)
+		name := column_name(leaf, br)
+
+		sel := len(branches) == 0
+		for _, b := range branches {
+			if match_column(b, name, br) {
+				sel = true
+				used[b] = true
+			}
+		}
+		if !sel {
+			continue
+		}
+
+		col, err := new_column(name, leaf, br)
+		if err != nil {
+			if len(branches) > 0 {
+				return nil, err
+			}
+			r.skipped = append(r.skipped, name)
+			continue
+		}
+		r.cols = append(r.cols, col)
+	}
+	r.schema = new_row_schema(r.cols)
+
+	for _, b := range branches {
+		if !used[b] {
+			return nil, fmt.Errorf("croot.NewReader: no branch [%s] in tree [%s]", b, t.GetName())
+		}
+	}
+
+	if len(branches) > 0 {
+		r.status = true
+		t.SetBranchStatus("*", false)
+		for _, col := range r.cols {
+			t.SetBranchStatus(col.Branch, true)
+			if count := col.Leaf.GetLeafCount(); count != nil {
+				t.SetBranchStatus(count.GetBranch().GetName(), true)
+			}
+		}
+	}
+	return r, nil
+}
+
+// Columns returns the description of the columns read by r.
+func (r *Reader) Columns() []Column {
+	return r.cols
+}
+
+// Skipped returns the names of the leaves r could not decode.
+func (r *Reader) Skipped() []string {
+	return r.skipped
+}
+
+// Read reads the given entry and returns the values of its columns.
+func (r *Reader) Read(entry int64) ([]interface{}, error) {
+	if r.tree.GetEntry(entry, 0) <= 0 {
+		return nil, fmt.Errorf("croot.Reader.Read: could not read entry [%d]", entry)
+	}
+	vals := make([]interface{}, len(r.cols))
+	for i := range r.cols {
+		vals[i] = r.cols[i].value()
+	}
+	return vals, nil
+}
+
+// Type returns the type of the struct holding the values of an entry (see
+// Row.Struct): a field per column, named after the column and tagged with
+// `croot:"column-name"`.
+func (r *Reader) Type() reflect.Type {
+	return r.schema.typ
+}
+
+// ReadRow reads the given entry and returns it as a Row.
+func (r *Reader) ReadRow(entry int64) (Row, error) {
+	vals, err := r.Read(entry)
+	if err != nil {
+		return Row{}, err
+	}
+	v := reflect.New(r.schema.typ).Elem()
+	for i, val := range vals {
+		v.Field(i).Set(reflect.ValueOf(val))
+	}
+	return Row{entry: entry, schema: r.schema, v: v}, nil
+}
+
+// Next reads the next entry (starting from the first one) and returns it
+// as a Row. io.EOF is returned once all the entries have been read.
+func (r *Reader) Next() (Row, error) {
+	if r.entry >= r.tree.GetEntries() {
+		return Row{}, io.EOF
+	}
+	row, err := r.ReadRow(r.entry)
+	if err != nil {
+		return row, err
+	}
+	r.entry++
+	return row, nil
+}
+
+// Close releases the resources held by r and activates all the branches
+// of the tree again.
+func (r *Reader) Close() {
+	if r.status {
+		r.tree.SetBranchStatus("*", true)
+		r.status = false
+	}
+}
+
+// LeafType returns the Go type of the values of leaf, as read by a Reader
+// or bound by Tree.SetBranchAddress: builtins, fixed-size arrays
+// ([N]T), variable-size arrays and std::vectors ([]T) and C-strings.
+func LeafType(leaf Leaf) (reflect.Type, error) {
+	c := (C.CRoot_Leaf)(unsafe.Pointer(leaf.(c_object).cptr()))
+	l := &leaf_impl{c: c}
+	col, err := new_column(l.GetName(), l, l.GetBranch().(*branch_impl))
+	if err != nil {
+		return nil, err
+	}
+	return col.Type, nil
+}
+
+// leaf_types maps the names of the leaves types to their Go equivalent
+var leaf_types = map[string]reflect.Type{
+	"Bool_t":             reflect.TypeOf(false),
+	"bool":               reflect.TypeOf(false),
+	"Char_t":             reflect.TypeOf(int8(0)),
+	"char":               reflect.TypeOf(int8(0)),
+	"UChar_t":            reflect.TypeOf(uint8(0)),
+	"unsigned char":      reflect.TypeOf(uint8(0)),
+	"Short_t":            reflect.TypeOf(int16(0)),
+	"short":              reflect.TypeOf(int16(0)),
+	"UShort_t":           reflect.TypeOf(uint16(0)),
+	"unsigned short":     reflect.TypeOf(uint16(0)),
+	"Int_t":              reflect.TypeOf(int32(0)),
+	"int":                reflect.TypeOf(int32(0)),
+	"UInt_t":             reflect.TypeOf(uint32(0)),
+	"unsigned int":       reflect.TypeOf(uint32(0)),
+	"Long_t":             reflect.TypeOf(int64(0)),
+	"long":               reflect.TypeOf(int64(0)),
+	"ULong_t":            reflect.TypeOf(uint64(0)),
+	"unsigned long":      reflect.TypeOf(uint64(0)),
+	"Long64_t":           reflect.TypeOf(int64(0)),
+	"long long":          reflect.TypeOf(int64(0)),
+	"ULong64_t":          reflect.TypeOf(uint64(0)),
+	"unsigned long long": reflect.TypeOf(uint64(0)),
+	"Float_t":            reflect.TypeOf(float32(0)),
+	"float":              reflect.TypeOf(float32(0)),
+	"Float16_t":          reflect.TypeOf(float32(0)),
+	"Double_t":           reflect.TypeOf(float64(0)),
+	"double":             reflect.TypeOf(float64(0)),
+	"Double32_t":         reflect.TypeOf(float64(0)),
+}
+
+// column_name returns "branch" for leaves alone in their branch (or
+// describing a member of a split object) and "branch.leaf" otherwise.
+func column_name(leaf *leaf_impl, br *branch_impl) string {
+	bname := br.GetName()
+	if leaf.ClassName() == "TLeafElement" {
+		return bname
+	}
+	lname := leaf.GetName()
+	if lname == bname || len(br.GetListOfLeaves()) == 1 {
+		return bname
+	}
+	return bname + "." + lname
+}
+
+// match_column returns whether the column name (of the leaf in branch br)
+// is selected by sel.
+func match_column(sel, name string, br *branch_impl) bool {
+	switch {
+	case sel == name, sel == br.GetName():
+		return true
+	case strings.HasPrefix(name, sel+"."), strings.HasPrefix(name, sel+"["):
+		return true
+	}
+	if mother := br.GetMother(); mother != nil {
+		return strings.TrimSuffix(mother.GetName(), ".") == strings.TrimSuffix(sel, ".")
+	}
+	return false
+}
+
+func new_column(name string, leaf *leaf_impl, br *branch_impl) (Column, error) {
+	col := Column{
+		Name:   name,
+		Branch: br.GetName(),
+		Leaf:   leaf,
+		c:      leaf.c,
+		fixed:  leaf.GetLenStatic(),
+		count:  leaf.GetLeafCount() != nil,
+	}
+
+	if leaf.ClassName() == "TLeafC" {
+		col.str = true
+		col.Type = reflect.TypeOf("")
+		return col, nil
+	}
+
+	tname := leaf.GetTypeName()
+	if tname == "string" || tname == "std::string" {
+		col.stdstr = true
+		col.Type = reflect.TypeOf("")
+		return col, nil
+	}
+	if strings.HasPrefix(tname, "vector<") && strings.HasSuffix(tname, ">") {
+		elem := strings.TrimSpace(tname[len("vector<") : len(tname)-1])
+		typ, ok := leaf_types[elem]
+		if !ok || typ.Kind() == reflect.Bool {
+			return col, fmt.Errorf("croot.NewReader: unsupported type [%s] for leaf [%s]", tname, name)
+		}
+		col.vector = true
+		col.Type = reflect.SliceOf(typ)
+		return col, nil
+	}
+
+	typ, ok := leaf_types[tname]
+	if !ok {
+		return col, fmt.Errorf("croot.NewReader: unsupported type [%s] for leaf [%s]", tname, name)
+	}
+	switch {
+	case col.count:
+		col.Type = reflect.SliceOf(typ)
+	case col.fixed > 1:
+		col.Type = reflect.ArrayOf(col.fixed, typ)
+	default:
+		col.Type = typ
+	}
+	return col, nil
+}
+
+// value returns a copy of the current value of the column.
+func (col *Column) value() interface{} {
+	ptr := C.CRoot_Leaf_GetValuePointer(col.c)
+	if col.str {
+		if ptr == nil {
+			return ""
+		}
+		return C.GoString((*C.char)(ptr))
+	}
+	if col.stdstr {
+		var str *C.char
+		var n C.int64_t
+		C.CRoot_Leaf_GetStringData(col.c, &str, &n)
+		if str == nil {
+			return ""
+		}
+		return C.GoStringN(str, C.int(n))
+	}
+	if col.vector {
+		var n C.int64_t
+		C.CRoot_Leaf_GetVectorData(col.c, &ptr, &n)
+		return copy_slice(col.Type, ptr, int(n))
+	}
+	if col.Type.Kind() != reflect.Slice {
+		if ptr == nil {
+			return reflect.Zero(col.Type).Interface()
+		}
+		return reflect.NewAt(col.Type, ptr).Elem().Interface()
+	}
+
+	return copy_slice(col.Type, ptr, int(C.CRoot_Leaf_GetLen(col.c)))
+}
+
+// copy_slice returns a slice of type typ holding a copy of the n elements
+// at ptr.
+func copy_slice(typ reflect.Type, ptr unsafe.Pointer, n int) interface{} {
+	slice := reflect.MakeSlice(typ, n, n)
+	if n > 0 && ptr != nil {
+		src := reflect.NewAt(reflect.ArrayOf(n, typ.Elem()), ptr).Elem()
+		reflect.Copy(slice, src)
+	}
+	return slice.Interface()
+}
+
+// EOF
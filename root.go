@@ -27,6 +27,13 @@ func (r *ROOT) GetFile(name string) File {
 	return &file_impl{c}
 }
 
+// EnableThreadSafety makes ROOT usable from several goroutines, each of
+// them working on its own files and objects.
+// It has to be called before any such goroutine is started.
+func EnableThreadSafety() {
+	C.CRoot_ROOT_EnableThreadSafety()
+}
+
 func init() {
 	GRoot = &ROOT{C.CRoot_gROOT}
 }
@@ -0,0 +1,263 @@
+package croot
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// row_schema describes the columns of the Rows read by a Reader
+type row_schema struct {
+	names []string
+	index map[string]int
+	typ   reflect.Type // struct type holding the values of a Row
+}
+
+func new_row_schema(cols []Column) *row_schema {
+	s := &row_schema{
+		names: make([]string, len(cols)),
+		index: make(map[string]int, len(cols)),
+	}
+	fields := make([]reflect.StructField, len(cols))
+	used := make(map[string]int, len(cols))
+	for i, col := range cols {
+		s.names[i] = col.Name
+		s.index[col.Name] = i
+
+		name := field_name(col.Name)
+		if n := used[name]; n > 0 {
+			used[name]++
+			name = fmt.Sprintf("%s_%d", name, n)
+		}
+		used[name]++
+		fields[i] = reflect.StructField{
+			Name: name,
+			Type: col.Type,
+			Tag:  reflect.StructTag(fmt.Sprintf("croot:%q", col.Name)),
+		}
+	}
+	s.typ = reflect.StructOf(fields)
+	return s
+}
+
+// field_name returns an exported Go identifier derived from a column name.
+func field_name(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	s := b.String()
+	if s == "" || !unicode.IsLetter([]rune(s)[0]) {
+		s = "X" + s
+	}
+	return s
+}
+
+// Row holds the values of the columns of an entry of a Tree, as read by a
+// Reader, in the order of the columns.
+type Row struct {
+	entry  int64
+	schema *row_schema
+	v      reflect.Value
+}
+
+// Entry returns the index of the entry the row was read from.
+func (row Row) Entry() int64 {
+	return row.entry
+}
+
+// Len returns the number of columns of the row.
+func (row Row) Len() int {
+	if row.schema == nil {
+		return 0
+	}
+	return len(row.schema.names)
+}
+
+// Names returns the names of the columns of the row.
+func (row Row) Names() []string {
+	if row.schema == nil {
+		return nil
+	}
+	return row.schema.names
+}
+
+// Value returns the value of the i-th column.
+func (row Row) Value(i int) interface{} {
+	return row.v.Field(i).Interface()
+}
+
+// Values returns the values of the columns of the row.
+func (row Row) Values() []interface{} {
+	vals := make([]interface{}, row.Len())
+	for i := range vals {
+		vals[i] = row.Value(i)
+	}
+	return vals
+}
+
+// Get returns the value of the named column, and whether the row has such
+// a column.
+func (row Row) Get(name string) (interface{}, bool) {
+	if row.schema == nil {
+		return nil, false
+	}
+	i, ok := row.schema.index[name]
+	if !ok {
+		return nil, false
+	}
+	return row.Value(i), true
+}
+
+// Struct returns a pointer to a struct holding the values of the row (see
+// Reader.Type).
+func (row Row) Struct() interface{} {
+	v := reflect.New(row.v.Type())
+	v.Elem().Set(row.v)
+	return v.Interface()
+}
+
+func (row Row) field(method, name string) (reflect.Value, error) {
+	if row.schema == nil {
+		return reflect.Value{}, fmt.Errorf("croot.Row.%s: no column [%s]", method, name)
+	}
+	i, ok := row.schema.index[name]
+	if !ok {
+		return reflect.Value{}, fmt.Errorf("croot.Row.%s: no column [%s]", method, name)
+	}
+	return row.v.Field(i), nil
+}
+
+// Int returns the value of the named column, which must hold a signed
+// integer.
+func (row Row) Int(name string) (int64, error) {
+	v, err := row.field("Int", name)
+	if err != nil {
+		return 0, err
+	}
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	}
+	return 0, fmt.Errorf("croot.Row.Int: column [%s] is of type [%s]", name, v.Type())
+}
+
+// Uint returns the value of the named column, which must hold an unsigned
+// integer.
+func (row Row) Uint(name string) (uint64, error) {
+	v, err := row.field("Uint", name)
+	if err != nil {
+		return 0, err
+	}
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return v.Uint(), nil
+	}
+	return 0, fmt.Errorf("croot.Row.Uint: column [%s] is of type [%s]", name, v.Type())
+}
+
+// Float returns the value of the named column, which must hold a number.
+// Integers are converted to float64.
+func (row Row) Float(name string) (float64, error) {
+	v, err := row.field("Float", name)
+	if err != nil {
+		return 0, err
+	}
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	}
+	return 0, fmt.Errorf("croot.Row.Float: column [%s] is of type [%s]", name, v.Type())
+}
+
+// Bool returns the value of the named column, which must hold a boolean.
+func (row Row) Bool(name string) (bool, error) {
+	v, err := row.field("Bool", name)
+	if err != nil {
+		return false, err
+	}
+	if v.Kind() != reflect.Bool {
+		return false, fmt.Errorf("croot.Row.Bool: column [%s] is of type [%s]", name, v.Type())
+	}
+	return v.Bool(), nil
+}
+
+// String returns the value of the named column, which must hold a string.
+func (row Row) String(name string) (string, error) {
+	v, err := row.field("String", name)
+	if err != nil {
+		return "", err
+	}
+	if v.Kind() != reflect.String {
+		return "", fmt.Errorf("croot.Row.String: column [%s] is of type [%s]", name, v.Type())
+	}
+	return v.String(), nil
+}
+
+// Floats returns the values of the named column, which must hold an array,
+// a slice or a std::vector of numbers. The values are converted to float64.
+func (row Row) Floats(name string) ([]float64, error) {
+	v, err := row.field("Floats", name)
+	if err != nil {
+		return nil, err
+	}
+	if k := v.Kind(); k != reflect.Array && k != reflect.Slice {
+		return nil, fmt.Errorf("croot.Row.Floats: column [%s] is of type [%s]", name, v.Type())
+	}
+	out := make([]float64, v.Len())
+	for i := range out {
+		e := v.Index(i)
+		switch e.Kind() {
+		case reflect.Float32, reflect.Float64:
+			out[i] = e.Float()
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			out[i] = float64(e.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			out[i] = float64(e.Uint())
+		default:
+			return nil, fmt.Errorf("croot.Row.Floats: column [%s] is of type [%s]", name, v.Type())
+		}
+	}
+	return out, nil
+}
+
+// Ints returns the values of the named column, which must hold an array,
+// a slice or a std::vector of integers. The values are converted to int64.
+func (row Row) Ints(name string) ([]int64, error) {
+	v, err := row.field("Ints", name)
+	if err != nil {
+		return nil, err
+	}
+	if k := v.Kind(); k != reflect.Array && k != reflect.Slice {
+		return nil, fmt.Errorf("croot.Row.Ints: column [%s] is of type [%s]", name, v.Type())
+	}
+	out := make([]int64, v.Len())
+	for i := range out {
+		e := v.Index(i)
+		switch e.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			out[i] = e.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			out[i] = int64(e.Uint())
+		default:
+			return nil, fmt.Errorf("croot.Row.Ints: column [%s] is of type [%s]", name, v.Type())
+		}
+	}
+	return out, nil
+}
+
+// EOF
@@ -0,0 +1,84 @@
+package croot
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+)
+
+// BranchSize holds the size statistics of a (top-level) branch, sub-branches
+// included.
+type BranchSize struct {
+	Name     string `json:"name"`
+	Entries  int64  `json:"entries"`
+	Baskets  int    `json:"baskets"`  // number of baskets written out, sub-branches included
+	TotBytes int64  `json:"totbytes"` // uncompressed size, in bytes
+	ZipBytes int64  `json:"zipbytes"` // compressed size, in bytes
+}
+
+// Ratio returns the compression ratio of the branch.
+func (bs BranchSize) Ratio() float64 {
+	if bs.ZipBytes == 0 {
+		return 0
+	}
+	return float64(bs.TotBytes) / float64(bs.ZipBytes)
+}
+
+// SizeSummary returns the size statistics of the top-level branches of t,
+// sorted by decreasing compressed size.
+func SizeSummary(t Tree) []BranchSize {
+	branches := t.GetListOfBranches()
+	sizes := make([]BranchSize, 0, len(branches))
+	for _, b := range branches {
+		if b == nil {
+			continue
+		}
+		sizes = append(sizes, BranchSize{
+			Name:     b.GetName(),
+			Entries:  b.GetEntries(),
+			Baskets:  write_baskets(b),
+			TotBytes: b.GetTotBytes("*"),
+			ZipBytes: b.GetZipBytes("*"),
+		})
+	}
+	sort.SliceStable(sizes, func(i, j int) bool {
+		return sizes[i].ZipBytes > sizes[j].ZipBytes
+	})
+	return sizes
+}
+
+// write_baskets returns the number of baskets written out by b and all its
+// sub-branches.
+func write_baskets(b Branch) int {
+	n := b.GetWriteBasket()
+	for _, sub := range b.GetListOfBranches() {
+		if sub == nil {
+			continue
+		}
+		n += write_baskets(sub)
+	}
+	return n
+}
+
+// WriteSizeSummary writes the size statistics of the branches of t as a
+// table into w.
+func WriteSizeSummary(w io.Writer, t Tree) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 1, ' ', tabwriter.AlignRight)
+	fmt.Fprintf(tw, "branch\tentries\tbaskets\ttot-bytes\tzip-bytes\tratio\tfraction\t\n")
+
+	zip := t.GetZipBytes()
+	for _, bs := range SizeSummary(t) {
+		frac := 0.0
+		if zip > 0 {
+			frac = 100 * float64(bs.ZipBytes) / float64(zip)
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%.2f\t%.1f%%\t\n",
+			bs.Name, bs.Entries, bs.Baskets, bs.TotBytes, bs.ZipBytes, bs.Ratio(), frac,
+		)
+	}
+	fmt.Fprintf(tw, "%s\t%d\t\t%d\t%d\t\t\t\n", "total", t.GetEntries(), t.GetTotBytes(), zip)
+	return tw.Flush()
+}
+
+// EOF
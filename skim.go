@@ -0,0 +1,73 @@
+package croot
+
+import (
+	"fmt"
+)
+
+// Skim copies the entries of src for which keep returns true into a new
+// tree of the same name, written into dst.
+// keep is called after the entry has been read, so values bound through
+// SetBranchAddress are up to date, whether their branches are copied over
+// or not. A nil keep selects all the entries.
+// If keepBranches is not empty, only the branches whose name matches one of
+// its elements (wildcards are allowed) are copied over.
+// All the branches of src are active again once Skim returns.
+// Skim returns the number of entries written out.
+func Skim(dst File, src Tree, keepBranches []string, keep func(entry int64) bool) (int64, error) {
+	if len(keepBranches) > 0 {
+		defer src.SetBranchStatus("*", true)
+		src.SetBranchStatus("*", false)
+		for _, name := range keepBranches {
+			if src.SetBranchStatus(name, true) == 0 {
+				return 0, fmt.Errorf("croot.Skim: no branch matching [%s] in tree [%s]", name, src.GetName())
+			}
+		}
+	}
+
+	nentries := src.GetEntries()
+	if nentries > 0 {
+		// make sure a chain has a tree to clone the structure from.
+		src.LoadTree(0)
+	}
+
+	if !dst.Cd("") {
+		return 0, fmt.Errorf("croot.Skim: could not cd into [%s]", dst.GetName())
+	}
+	out, err := src.CloneTree(0)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(keepBranches) > 0 {
+		// the branches bound by the caller are read for keep, even if
+		// they are not copied over.
+		if t, ok := src.(interface {
+			bound_branches() []string
+		}); ok {
+			for _, name := range t.bound_branches() {
+				src.SetBranchStatus(name, true)
+			}
+		}
+	}
+
+	n := int64(0)
+	for i := int64(0); i < nentries; i++ {
+		if src.GetEntry(i, 0) < 0 {
+			return n, fmt.Errorf("croot.Skim: could not read entry [%d] of tree [%s]", i, src.GetName())
+		}
+		if keep != nil && !keep(i) {
+			continue
+		}
+		if _, err = out.Fill(); err != nil {
+			return n, err
+		}
+		n++
+	}
+
+	if out.Write("", 0, 0) <= 0 {
+		return n, fmt.Errorf("croot.Skim: could not write tree [%s] to [%s]", out.GetName(), dst.GetName())
+	}
+	return n, nil
+}
+
+// EOF
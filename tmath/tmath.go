@@ -0,0 +1,247 @@
+// Package tmath wraps the TMath namespace of ROOT.
+package tmath
+
+// #include "croot/croot.h"
+// #cgo CFLAGS: -I../bindings/inc
+import "C"
+
+// Mathematical constants, as defined by TMath
+const (
+	Pi       = 3.14159265358979323846
+	TwoPi    = 2 * Pi
+	PiOver2  = Pi / 2
+	E        = 2.71828182845904523536
+	Ln10     = 2.30258509299404568402
+	LogE     = 0.43429448190325182765
+	Sqrt2    = 1.4142135623730950488016887242097
+	DegToRad = Pi / 180.0
+	RadToDeg = 180.0 / Pi
+)
+
+// Sin returns the sine of x
+func Sin(x float64) float64 {
+	return float64(C.CRoot_Math_Sin(C.double(x)))
+}
+
+// Cos returns the cosine of x
+func Cos(x float64) float64 {
+	return float64(C.CRoot_Math_Cos(C.double(x)))
+}
+
+// Tan returns the tangent of x
+func Tan(x float64) float64 {
+	return float64(C.CRoot_Math_Tan(C.double(x)))
+}
+
+// SinH returns the hyperbolic sine of x
+func SinH(x float64) float64 {
+	return float64(C.CRoot_Math_SinH(C.double(x)))
+}
+
+// CosH returns the hyperbolic cosine of x
+func CosH(x float64) float64 {
+	return float64(C.CRoot_Math_CosH(C.double(x)))
+}
+
+// TanH returns the hyperbolic tangent of x
+func TanH(x float64) float64 {
+	return float64(C.CRoot_Math_TanH(C.double(x)))
+}
+
+// ASin returns the arc sine of x
+func ASin(x float64) float64 {
+	return float64(C.CRoot_Math_ASin(C.double(x)))
+}
+
+// ACos returns the arc cosine of x
+func ACos(x float64) float64 {
+	return float64(C.CRoot_Math_ACos(C.double(x)))
+}
+
+// ATan returns the arc tangent of x
+func ATan(x float64) float64 {
+	return float64(C.CRoot_Math_ATan(C.double(x)))
+}
+
+// ASinH returns the hyperbolic arc sine of x
+func ASinH(x float64) float64 {
+	return float64(C.CRoot_Math_ASinH(C.double(x)))
+}
+
+// ACosH returns the hyperbolic arc cosine of x
+func ACosH(x float64) float64 {
+	return float64(C.CRoot_Math_ACosH(C.double(x)))
+}
+
+// ATanH returns the hyperbolic arc tangent of x
+func ATanH(x float64) float64 {
+	return float64(C.CRoot_Math_ATanH(C.double(x)))
+}
+
+// ATan2 returns the arc tangent of y/x, using the signs of both to
+// determine the quadrant
+func ATan2(y, x float64) float64 {
+	return float64(C.CRoot_Math_ATan2(C.double(y), C.double(x)))
+}
+
+// Hypot returns sqrt(x*x + y*y)
+func Hypot(x, y float64) float64 {
+	return float64(C.CRoot_Math_Hypot(C.double(x), C.double(y)))
+}
+
+// Sqrt returns the square root of x
+func Sqrt(x float64) float64 {
+	return float64(C.CRoot_Math_Sqrt(C.double(x)))
+}
+
+// Ceil returns the smallest integral value not less than x
+func Ceil(x float64) float64 {
+	return float64(C.CRoot_Math_Ceil(C.double(x)))
+}
+
+// CeilNint returns the nearest integer of Ceil(x)
+func CeilNint(x float64) int {
+	return int(C.CRoot_Math_CeilNint(C.double(x)))
+}
+
+// Floor returns the largest integral value not greater than x
+func Floor(x float64) float64 {
+	return float64(C.CRoot_Math_Floor(C.double(x)))
+}
+
+// FloorNint returns the nearest integer of Floor(x)
+func FloorNint(x float64) int {
+	return int(C.CRoot_Math_FloorNint(C.double(x)))
+}
+
+// Exp returns the base-e exponential of x
+func Exp(x float64) float64 {
+	return float64(C.CRoot_Math_Exp(C.double(x)))
+}
+
+// Ldexp returns x * 2**exp
+func Ldexp(x float64, exp int) float64 {
+	return float64(C.CRoot_Math_Ldexp(C.double(x), C.int32_t(exp)))
+}
+
+// Factorial returns n!
+func Factorial(n int) float64 {
+	return float64(C.CRoot_Math_Factorial(C.int32_t(n)))
+}
+
+// Power returns x**y
+func Power(x, y float64) float64 {
+	return float64(C.CRoot_Math_Power(C.double(x), C.double(y)))
+}
+
+// Log returns the natural logarithm of x
+func Log(x float64) float64 {
+	return float64(C.CRoot_Math_Log(C.double(x)))
+}
+
+// Log2 returns the binary logarithm of x
+func Log2(x float64) float64 {
+	return float64(C.CRoot_Math_Log2(C.double(x)))
+}
+
+// Log10 returns the decimal logarithm of x
+func Log10(x float64) float64 {
+	return float64(C.CRoot_Math_Log10(C.double(x)))
+}
+
+// -- statistics --
+
+// Gaus returns the value of a gaussian of mean and sigma at x.
+// If norm is true, the result is divided by sqrt(2*Pi)*sigma.
+func Gaus(x, mean, sigma float64, norm bool) float64 {
+	return float64(C.CRoot_Math_Gaus(C.double(x), C.double(mean), C.double(sigma), bool2c(norm)))
+}
+
+// Landau returns the value of the Landau distribution of most probable
+// value mpv and width sigma at x.
+// If norm is true, the result is divided by sigma.
+func Landau(x, mpv, sigma float64, norm bool) float64 {
+	return float64(C.CRoot_Math_Landau(C.double(x), C.double(mpv), C.double(sigma), bool2c(norm)))
+}
+
+// BreitWigner returns the value of a (normalized) Breit-Wigner of mean
+// and width gamma at x.
+func BreitWigner(x, mean, gamma float64) float64 {
+	return float64(C.CRoot_Math_BreitWigner(C.double(x), C.double(mean), C.double(gamma)))
+}
+
+// Poisson returns the Poisson probability of x occurences given a mean
+// par, using the Gamma function for non-integer x.
+func Poisson(x, par float64) float64 {
+	return float64(C.CRoot_Math_Poisson(C.double(x), C.double(par)))
+}
+
+// PoissonI returns the Poisson probability of int(x) occurences given a
+// mean par.
+func PoissonI(x, par float64) float64 {
+	return float64(C.CRoot_Math_PoissonI(C.double(x), C.double(par)))
+}
+
+// Prob returns the probability that an observed chi2 exceeds chi2 by
+// chance, for ndf degrees of freedom.
+func Prob(chi2 float64, ndf int) float64 {
+	return float64(C.CRoot_Math_Prob(C.double(chi2), C.int32_t(ndf)))
+}
+
+// Erf returns the error function of x
+func Erf(x float64) float64 {
+	return float64(C.CRoot_Math_Erf(C.double(x)))
+}
+
+// Erfc returns the complementary error function of x
+func Erfc(x float64) float64 {
+	return float64(C.CRoot_Math_Erfc(C.double(x)))
+}
+
+// ErfInverse returns the inverse error function of x
+func ErfInverse(x float64) float64 {
+	return float64(C.CRoot_Math_ErfInverse(C.double(x)))
+}
+
+// ErfcInverse returns the inverse complementary error function of x
+func ErfcInverse(x float64) float64 {
+	return float64(C.CRoot_Math_ErfcInverse(C.double(x)))
+}
+
+// Freq returns the normal cumulative distribution function at x
+func Freq(x float64) float64 {
+	return float64(C.CRoot_Math_Freq(C.double(x)))
+}
+
+// ChisquareQuantile returns the p-quantile of the chi2 distribution with
+// ndf degrees of freedom.
+func ChisquareQuantile(p, ndf float64) float64 {
+	return float64(C.CRoot_Math_ChisquareQuantile(C.double(p), C.double(ndf)))
+}
+
+// Student returns the density of the Student's t distribution with ndf
+// degrees of freedom at t.
+func Student(t, ndf float64) float64 {
+	return float64(C.CRoot_Math_Student(C.double(t), C.double(ndf)))
+}
+
+// StudentI returns the cumulative distribution function of the Student's
+// t distribution with ndf degrees of freedom at t.
+func StudentI(t, ndf float64) float64 {
+	return float64(C.CRoot_Math_StudentI(C.double(t), C.double(ndf)))
+}
+
+// StudentQuantile returns the p-quantile of the Student's t distribution
+// with ndf degrees of freedom (the upper tail one if lowerTail is false.)
+func StudentQuantile(p, ndf float64, lowerTail bool) float64 {
+	return float64(C.CRoot_Math_StudentQuantile(C.double(p), C.double(ndf), bool2c(lowerTail)))
+}
+
+func bool2c(b bool) C.CRoot_Bool {
+	if b {
+		return C.CRoot_Bool(1)
+	}
+	return C.CRoot_Bool(0)
+}
+
+// EOF
@@ -0,0 +1,158 @@
+package tmath_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/go-hep/croot/tmath"
+)
+
+const eps = 1e-9
+
+func almostEq(a, b, tol float64) bool {
+	if a == b {
+		return true
+	}
+	return math.Abs(a-b) <= tol*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
+
+func TestUnary(t *testing.T) {
+	for _, table := range []struct {
+		name string
+		fct  func(float64) float64
+		ref  func(float64) float64
+		xs   []float64
+	}{
+		{"Sin", tmath.Sin, math.Sin, []float64{-2, 0, 0.5, math.Pi}},
+		{"Cos", tmath.Cos, math.Cos, []float64{-2, 0, 0.5, math.Pi}},
+		{"Tan", tmath.Tan, math.Tan, []float64{-1, 0, 0.5}},
+		{"SinH", tmath.SinH, math.Sinh, []float64{-2, 0, 0.5, 3}},
+		{"CosH", tmath.CosH, math.Cosh, []float64{-2, 0, 0.5, 3}},
+		{"TanH", tmath.TanH, math.Tanh, []float64{-2, 0, 0.5, 3}},
+		{"ASin", tmath.ASin, math.Asin, []float64{-1, 0, 0.5, 1}},
+		{"ACos", tmath.ACos, math.Acos, []float64{-1, 0, 0.5, 1}},
+		{"ATan", tmath.ATan, math.Atan, []float64{-10, 0, 0.5, 10}},
+		{"ASinH", tmath.ASinH, math.Asinh, []float64{-2, 0, 0.5, 3}},
+		{"ACosH", tmath.ACosH, math.Acosh, []float64{1, 1.5, 3}},
+		{"ATanH", tmath.ATanH, math.Atanh, []float64{-0.5, 0, 0.5}},
+		{"Sqrt", tmath.Sqrt, math.Sqrt, []float64{0, 0.5, 2, 1e6}},
+		{"Ceil", tmath.Ceil, math.Ceil, []float64{-1.5, 0, 0.5, 2}},
+		{"Floor", tmath.Floor, math.Floor, []float64{-1.5, 0, 0.5, 2}},
+		{"Exp", tmath.Exp, math.Exp, []float64{-2, 0, 0.5, 10}},
+		{"Log", tmath.Log, math.Log, []float64{0.5, 1, 2, 1e6}},
+		{"Log2", tmath.Log2, math.Log2, []float64{0.5, 1, 2, 1024}},
+		{"Log10", tmath.Log10, math.Log10, []float64{0.5, 1, 2, 1e6}},
+		{"Erf", tmath.Erf, math.Erf, []float64{-2, 0, 0.5, 3}},
+		{"Erfc", tmath.Erfc, math.Erfc, []float64{-2, 0, 0.5, 3}},
+		{"ErfInverse", tmath.ErfInverse, math.Erfinv, []float64{-0.9, 0, 0.5, 0.99}},
+		{"ErfcInverse", tmath.ErfcInverse, math.Erfcinv, []float64{0.1, 1, 1.5}},
+	} {
+		for _, x := range table.xs {
+			got := table.fct(x)
+			want := table.ref(x)
+			if !almostEq(got, want, 1e-6) {
+				t.Errorf("%s(%v): got %v, want %v", table.name, x, got, want)
+			}
+		}
+	}
+}
+
+func TestBinary(t *testing.T) {
+	for _, v := range [][2]float64{{1, 1}, {-1, 2}, {3, -4}} {
+		if got, want := tmath.ATan2(v[0], v[1]), math.Atan2(v[0], v[1]); !almostEq(got, want, eps) {
+			t.Errorf("ATan2(%v, %v): got %v, want %v", v[0], v[1], got, want)
+		}
+		if got, want := tmath.Hypot(v[0], v[1]), math.Hypot(v[0], v[1]); !almostEq(got, want, eps) {
+			t.Errorf("Hypot(%v, %v): got %v, want %v", v[0], v[1], got, want)
+		}
+	}
+
+	if got, want := tmath.Power(2, 10), math.Pow(2, 10); !almostEq(got, want, eps) {
+		t.Errorf("Power(2, 10): got %v, want %v", got, want)
+	}
+	if got, want := tmath.Ldexp(0.75, 4), math.Ldexp(0.75, 4); !almostEq(got, want, eps) {
+		t.Errorf("Ldexp(0.75, 4): got %v, want %v", got, want)
+	}
+	if got := tmath.Factorial(5); got != 120 {
+		t.Errorf("Factorial(5): got %v, want 120", got)
+	}
+	if got := tmath.CeilNint(1.2); got != 2 {
+		t.Errorf("CeilNint(1.2): got %v, want 2", got)
+	}
+	if got := tmath.FloorNint(1.8); got != 1 {
+		t.Errorf("FloorNint(1.8): got %v, want 1", got)
+	}
+}
+
+func TestStatistics(t *testing.T) {
+	const mean = 1.5
+	const sigma = 0.5
+	for _, x := range []float64{-1, 0, 1.5, 2} {
+		g := math.Exp(-0.5 * (x - mean) * (x - mean) / (sigma * sigma))
+		if got := tmath.Gaus(x, mean, sigma, false); !almostEq(got, g, eps) {
+			t.Errorf("Gaus(%v): got %v, want %v", x, got, g)
+		}
+		gn := g / (math.Sqrt(2*math.Pi) * sigma)
+		if got := tmath.Gaus(x, mean, sigma, true); !almostEq(got, gn, eps) {
+			t.Errorf("Gaus(%v, norm): got %v, want %v", x, got, gn)
+		}
+
+		const gamma = 2.0
+		bw := gamma / (2 * math.Pi) / ((x-mean)*(x-mean) + gamma*gamma/4)
+		if got := tmath.BreitWigner(x, mean, gamma); !almostEq(got, bw, eps) {
+			t.Errorf("BreitWigner(%v): got %v, want %v", x, got, bw)
+		}
+
+		// the normal CDF
+		freq := 0.5 * math.Erfc(-x/math.Sqrt2)
+		if got := tmath.Freq(x); !almostEq(got, freq, 1e-6) {
+			t.Errorf("Freq(%v): got %v, want %v", x, got, freq)
+		}
+	}
+
+	for _, n := range []float64{0, 1, 3, 10} {
+		const mu = 3.0
+		lgam, _ := math.Lgamma(n + 1)
+		p := math.Exp(n*math.Log(mu) - mu - lgam)
+		if got := tmath.Poisson(n, mu); !almostEq(got, p, 1e-6) {
+			t.Errorf("Poisson(%v, %v): got %v, want %v", n, mu, got, p)
+		}
+		if got := tmath.PoissonI(n, mu); !almostEq(got, p, 1e-6) {
+			t.Errorf("PoissonI(%v, %v): got %v, want %v", n, mu, got, p)
+		}
+	}
+
+	// for 2 degrees of freedom, the chi2 survival function is exp(-chi2/2)
+	for _, chi2 := range []float64{0.5, 1, 4, 10} {
+		want := math.Exp(-chi2 / 2)
+		if got := tmath.Prob(chi2, 2); !almostEq(got, want, 1e-6) {
+			t.Errorf("Prob(%v, 2): got %v, want %v", chi2, got, want)
+		}
+		q := tmath.ChisquareQuantile(1-want, 2)
+		if !almostEq(q, chi2, 1e-6) {
+			t.Errorf("ChisquareQuantile(%v, 2): got %v, want %v", 1-want, q, chi2)
+		}
+	}
+
+	// Student with 1 degree of freedom is the Cauchy distribution
+	for _, x := range []float64{-2, 0, 1, 3} {
+		want := 1 / (math.Pi * (1 + x*x))
+		if got := tmath.Student(x, 1); !almostEq(got, want, 1e-6) {
+			t.Errorf("Student(%v, 1): got %v, want %v", x, got, want)
+		}
+		cdf := 0.5 + math.Atan(x)/math.Pi
+		if got := tmath.StudentI(x, 1); !almostEq(got, cdf, 1e-6) {
+			t.Errorf("StudentI(%v, 1): got %v, want %v", x, got, cdf)
+		}
+		if got := tmath.StudentQuantile(cdf, 1, true); !almostEq(got, x, 1e-6) {
+			t.Errorf("StudentQuantile(%v, 1): got %v, want %v", cdf, got, x)
+		}
+	}
+
+	// Landau has its maximum close to its most probable value
+	if l0, l1 := tmath.Landau(0, 0, 1, false), tmath.Landau(2, 0, 1, false); l0 <= l1 {
+		t.Errorf("Landau: expected a decreasing tail (got %v <= %v)", l0, l1)
+	}
+}
+
+// EOF
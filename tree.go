@@ -23,6 +23,7 @@ import (
 	"fmt"
 	"os"
 	"reflect"
+	"strings"
 	"unsafe"
 
 	"github.com/go-hep/croot/cmem"
@@ -32,32 +33,71 @@ import (
 type Tree interface {
 	Object
 
+	AddBranchToCache(name string, subbranches bool)
+	AddFriend(treename, filename, alias string) (Tree, error)
 	Branch(name string, obj interface{}, bufsiz, splitlevel int) (Branch, error)
 	Branch2(name string, objaddr interface{}, leaflist string, bufsiz int) (Branch, error)
+	BuildGoIndex(major, minor string) (int, error)
+	BuildIndex(major, minor string) (int, error)
+	CloneTree(nentries int64) (Tree, error)
+	CopyTree(selection string) (Tree, error)
 	Delete()
+	Draw(varexp, selection string, option Option, nentries, firstentry int64) (int64, error)
 	Fill() (int, error)
 	GetBranch(name string) Branch
 	GetEntries() int64
 	GetEntry(entry int64, getall int) int
+	GetEntryList() EntryList
+	GetEntryNumber(entry int64) int64
+	GetEntryFromList(i int64, getall int) int
+	GetEntryNumberWithIndex(major, minor int64) int64
+	GetEntryWithIndex(major, minor int64) int
+	GetAutoFlush() int64
+	GetAutoSave() int64
+	GetEstimate() int64
 	GetLeaf(name string) Leaf
 	GetListOfBranches() []Branch
 	GetListOfLeaves() []Leaf
 	GetSelectedRows() int64
+	GetTotBytes() int64
+	GetTreeNumber() int
 	GetVal(i int) []float64
 	GetV1() []float64
 	GetV2() []float64
 	GetV3() []float64
 	GetV4() []float64
 	GetW() []float64
+	GetZipBytes() int64
 	LoadTree(entry int64) int64
+	SetAutoFlush(autof int64)
+	SetAutoSave(autos int64)
 	SetBranchAddress(name string, obj interface{}) int32
 	SetBranchStatus(name string, status bool) uint32
+	SetCacheLearnEntries(n int)
+	SetCacheSize(cachesize int64)
+	SetEntryList(elist EntryList)
+	SetEstimate(nentries int64)
+	StopCacheLearningPhase()
 	Write(name string, option, bufsize int) int
 }
 
 type tree_impl struct {
 	c        C.CRoot_Tree
 	branches map[string]*gobranch
+	treenum  int                   // current tree number (for chains)
+	friends  map[string]*tree_impl // friend trees, indexed by alias
+	goindex  *goindex              // pure-Go index, when ROOT has none
+	cache    bool                  // a TTreeCache is set: bound branches are added to it
+	learning bool                  // the cache learning phase is to be stopped at the next GetEntry
+}
+
+// bound_branches returns the names of the branches bound to Go values
+func (t *tree_impl) bound_branches() []string {
+	names := make([]string, 0, len(t.branches))
+	for name := range t.branches {
+		names = append(names, name)
+	}
+	return names
 }
 
 func (t *tree_impl) cptr() C.CRoot_Object {
@@ -99,12 +139,19 @@ type gobranch struct {
 	addr  unsafe.Pointer // address of that C-value buffer
 	valid bool           // whether the branch has been correctly connected to the Tree C-buffer
 	br    *branch_impl
+	col   *Column      // arrays, std::vectors and C-strings, copied from their leaf buffer
+	cols  []Column     // leaves of a leaflist, copied into the fields of a struct
+	fill  func() error // copies the go-value into the branch buffer (written-only branches)
+	free  func()       // releases the C-allocated branch buffer (written-only branches)
 }
 
 func (br *gobranch) get_c_branch(t *tree_impl, name string) unsafe.Pointer {
 	//fmt.Printf("::: get_c_branch...\n")
 	var ptr unsafe.Pointer
 
+	// branches of friend trees are looked up in those trees directly.
+	t, name = t.resolve_friend(name)
+
 	c_name := C.CString(name)
 	defer C.free(unsafe.Pointer(c_name))
 
@@ -182,6 +229,39 @@ func (br *gobranch) update_from_c(t *tree_impl, name string) error {
 		return fmt.Errorf("croot.update_from_c: invalid branch [%v]", name)
 	}
 
+	if br.fill != nil {
+		// written-only branch: nothing to read back.
+		return nil
+	}
+
+	if br.col != nil {
+		if !br.valid {
+			col, _, err := t.leaf_column(name)
+			if err != nil {
+				return err
+			}
+			br.col = &col
+			br.valid = true
+		}
+		br.v.Set(reflect.ValueOf(br.col.value()))
+		return nil
+	}
+
+	if br.cols != nil {
+		if !br.valid {
+			cols, _, err := t.leaflist_columns(name, br.v.Type())
+			if err != nil {
+				return err
+			}
+			br.cols = cols
+			br.valid = true
+		}
+		for i := range br.cols {
+			br.v.Field(i).Set(reflect.ValueOf(br.cols[i].value()))
+		}
+		return nil
+	}
+
 	if !br.valid {
 		//fmt.Printf(">>> br.c=%v (%v)\n", br.c.UnsafeAddr(), name)
 		ptr := br.get_c_branch(t, name)
@@ -200,6 +280,29 @@ func (br *gobranch) update_from_c(t *tree_impl, name string) error {
 	return nil
 }
 
+// resolve_friend returns the tree holding the branch name, and the name of
+// that branch in that tree, stripping the alias of friend trees.
+// Overlapping aliases (e.g. "cal" and "cal.v2") are matched longest first.
+func (t *tree_impl) resolve_friend(name string) (*tree_impl, string) {
+	var (
+		ftree *tree_impl
+		alias string
+	)
+	for a, ft := range t.friends {
+		if len(a) <= len(alias) || !strings.HasPrefix(name, a+".") {
+			continue
+		}
+		bname := name[len(a)+1:]
+		if ft.GetBranch(bname) != nil || ft.GetBranch(bname+".") != nil {
+			ftree, alias = ft, a
+		}
+	}
+	if ftree == nil {
+		return t, name
+	}
+	return ftree, name[len(alias)+1:]
+}
+
 func NewTree(name, title string, splitlevel int) Tree {
 	c_name := C.CString(name)
 	defer C.free(unsafe.Pointer(c_name))
@@ -210,12 +313,66 @@ func NewTree(name, title string, splitlevel int) Tree {
 	return &tree_impl{c: t, branches: b}
 }
 
+// Delete deletes the Tree, and releases the C buffers of the branches
+// created from Go values (see Branch and Branch2).
 func (t *tree_impl) Delete() {
 	C.CRoot_Tree_delete(t.c)
 	t.c = nil
+	for _, br := range t.branches {
+		if br.free != nil {
+			br.free()
+		}
+	}
 	t.branches = nil
 }
 
+// AddBranchToCache adds the branch name (a wildcarded expression or "*"
+// for all the branches) to the TTreeCache of t, along with its sub-branches
+// if subbranches is true.
+func (t *tree_impl) AddBranchToCache(name string, subbranches bool) {
+	c_name := C.CString(name)
+	defer C.free(unsafe.Pointer(c_name))
+	C.CRoot_Tree_AddBranchToCache(t.c, c_name, bool2c(subbranches))
+}
+
+// AddFriend adds the tree treename from file filename (or from the current
+// directory if filename is empty) as a friend of t.
+// Branches of the friend tree are then available as "alias.branch", alias
+// defaulting to treename.
+// Friend entries are read along with those of t: by entry number or, if
+// the friend tree has an index (see BuildIndex), by index values.
+func (t *tree_impl) AddFriend(treename, filename, alias string) (Tree, error) {
+	if alias == "" {
+		alias = treename
+	}
+	name := treename
+	if alias != treename {
+		name = alias + "=" + treename
+	}
+	c_name := C.CString(name)
+	defer C.free(unsafe.Pointer(c_name))
+	c_fname := C.CString(filename)
+	defer C.free(unsafe.Pointer(c_fname))
+
+	c := C.CRoot_Tree_AddFriend(t.c, c_name, c_fname)
+	if c == nil {
+		return nil, fmt.Errorf("croot.Tree.AddFriend: could not add friend tree [%s] from file [%s]", treename, filename)
+	}
+
+	ft := &tree_impl{c: c, branches: make(map[string]*gobranch)}
+	if t.friends == nil {
+		t.friends = make(map[string]*tree_impl)
+	}
+	t.friends[alias] = ft
+	return ft, nil
+}
+
+// Branch creates a branch holding obj, a pointer to a struct (registered
+// with Reflex), to a slice of builtins (a std::vector) or to a string (a
+// std::string).
+// The std::vector or std::string the value is copied into at each Fill is
+// owned by t and released by Delete: it is not released if t is deleted
+// along with its File instead.
 func (t *tree_impl) Branch(name string, obj interface{}, bufsiz, splitlevel int) (Branch, error) {
 	c_name := C.CString(name)
 	defer C.free(unsafe.Pointer(c_name))
@@ -225,6 +382,10 @@ func (t *tree_impl) Branch(name string, obj interface{}, bufsiz, splitlevel int)
 		return nil, fmt.Errorf("croot.Tree.Branch: takes a pointer to a struct (got %v)", ptr.Type())
 	}
 	val := reflect.Indirect(ptr)
+	switch val.Type().Kind() {
+	case reflect.Slice, reflect.String:
+		return t.branch_stl(name, val, bufsiz, splitlevel)
+	}
 	if val.Type().Kind() != reflect.Struct {
 		return nil, fmt.Errorf("croot.Tree.Branch: takes a pointer to a struct (got %v)", ptr.Type())
 	}
@@ -246,6 +407,76 @@ func (t *tree_impl) Branch(name string, obj interface{}, bufsiz, splitlevel int)
 	return br.br, nil
 }
 
+// branch_stl creates a std::vector or std::string branch, whose content is
+// assigned from the Go value at each Fill.
+func (t *tree_impl) branch_stl(name string, val reflect.Value, bufsiz, splitlevel int) (Branch, error) {
+	classname := "string"
+	if val.Type().Kind() == reflect.Slice {
+		elem, ok := cxx_builtins[val.Type().Elem().Kind()]
+		if !ok || val.Type().Elem().Kind() == reflect.Bool {
+			return nil, fmt.Errorf("croot.Tree.Branch: takes a pointer to a slice of builtins (got *%v)", val.Type())
+		}
+		classname = "vector<" + elem + ">"
+	}
+	c_name := C.CString(name)
+	defer C.free(unsafe.Pointer(c_name))
+	c_classname := C.CString(classname)
+	defer C.free(unsafe.Pointer(c_classname))
+
+	cls := C.CRoot_Class_GetClass(c_classname)
+	if cls == nil {
+		return nil, fmt.Errorf("croot.Tree.Branch: no dictionary for class [%s]", classname)
+	}
+	br := &gobranch{v: val}
+	br.cptr = C.CRoot_Class_New(cls)
+	br.addr = unsafe.Pointer(&br.cptr)
+
+	b := C.CRoot_Tree_Branch(t.c, c_name, c_classname, br.addr, C.int32_t(bufsiz), C.int32_t(splitlevel))
+	if b == nil {
+		C.CRoot_Class_Destructor(cls, br.cptr)
+		return nil, fmt.Errorf("croot.Tree.Branch: could not create branch [%s] (class [%s])", name, classname)
+	}
+	br.br = &branch_impl{c: b}
+	br.free = func() {
+		C.CRoot_Class_Destructor(cls, br.cptr)
+		br.cptr = nil
+	}
+	br.fill = func() error {
+		var ok C.CRoot_Bool
+		if val.Kind() == reflect.String {
+			str := val.String()
+			c_str := C.CString(str)
+			defer C.free(unsafe.Pointer(c_str))
+			ok = C.CRoot_Branch_SetStringData(b, c_str, C.int64_t(len(str)))
+		} else {
+			var data unsafe.Pointer
+			if val.Len() > 0 {
+				data = unsafe.Pointer(val.Pointer())
+			}
+			ok = C.CRoot_Branch_SetVectorData(b, data, C.int64_t(val.Len()))
+		}
+		if !c2bool(ok) {
+			return fmt.Errorf("croot.Tree.Fill: could not assign branch [%s] (class [%s])", name, classname)
+		}
+		return nil
+	}
+	t.branches[name] = br
+	return br.br, nil
+}
+
+// Branch2 creates a branch described by leaflist ("x/D", "p[3]/F", ...)
+// holding objaddr, a pointer to:
+//   - a builtin or an array of builtins,
+//   - a string, for "name/C" leaves,
+//   - a slice of builtins, for variable-size arrays ("v[n]/F", the count
+//     branch n being filled with the length of the slice by the caller),
+//   - a struct of builtins and arrays of builtins, a field per leaf.
+//
+// ROOT lays out the leaves of a leaflist without padding: the fields of
+// structs are packed accordingly at each Fill.
+// Booleans, strings, slices and structs are packed into a C buffer owned by
+// t and released by Delete: it is not released if t is deleted along with
+// its File instead.
 func (t *tree_impl) Branch2(name string, objaddr interface{}, leaflist string, bufsiz int) (Branch, error) {
 	c_name := C.CString(name)
 	defer C.free(unsafe.Pointer(c_name))
@@ -255,12 +486,23 @@ func (t *tree_impl) Branch2(name string, objaddr interface{}, leaflist string, b
 		return nil, fmt.Errorf("croot.Tree.Branch: takes a pointer to a builtin (got %v)", ptr.Type())
 	}
 	val := reflect.Indirect(ptr)
-	switch k := val.Type().Kind(); k {
+	typ := val.Type()
+	if typ.Kind() == reflect.Array {
+		// fixed-size arrays of builtins are described by "name[N]/T" leaves
+		typ = typ.Elem()
+	}
+	switch k := typ.Kind(); k {
 	default:
 		// ok.
-	case reflect.Ptr, reflect.Struct, reflect.String, reflect.Array,
-		reflect.Slice:
-		return nil, fmt.Errorf("croot.Tree.Branch: takes a pointer to a builtin (got %v)", ptr.Type())
+	case reflect.Bool:
+		return t.branch_packed(name, val, leaflist, bufsiz)
+	case reflect.String, reflect.Slice, reflect.Struct:
+		if val.Kind() != reflect.Array {
+			return t.branch_packed(name, val, leaflist, bufsiz)
+		}
+		fallthrough
+	case reflect.Ptr, reflect.Array:
+		return nil, fmt.Errorf("croot.Tree.Branch: takes a pointer to a builtin or to an array of builtins (got %v)", ptr.Type())
 	}
 	br := &gobranch{v: val, c: cmem.ValueOf(val.Interface())}
 	// register the type with Reflex
@@ -278,10 +520,258 @@ func (t *tree_impl) Branch2(name string, objaddr interface{}, leaflist string, b
 	return br.br, nil
 }
 
+// branch_packed creates a leaflist branch for the values cmem can not
+// describe: the Go value is copied into a C buffer, laid out as ROOT expects
+// it, at each Fill.
+// The buffer grows with strings and slices, the branch being re-addressed
+// when it moves.
+func (t *tree_impl) branch_packed(name string, val reflect.Value, leaflist string, bufsiz int) (Branch, error) {
+	typ := val.Type()
+	var offsets []uintptr
+	size := 0
+	switch typ.Kind() {
+	case reflect.Bool:
+		size = 1
+	case reflect.Array:
+		size = typ.Len()
+	case reflect.String:
+		size = default_string_len + 1
+	case reflect.Slice:
+		if !is_builtin(typ.Elem().Kind()) {
+			return nil, fmt.Errorf("croot.Tree.Branch: takes a pointer to a slice of builtins (got *%v)", typ)
+		}
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			ft := typ.Field(i).Type
+			et := ft
+			if et.Kind() == reflect.Array {
+				et = et.Elem()
+			}
+			if !is_builtin(et.Kind()) {
+				return nil, fmt.Errorf(
+					"croot.Tree.Branch: field [%s] of [%v] is not a builtin nor an array of builtins (got %v)",
+					typ.Field(i).Name, typ, ft,
+				)
+			}
+			offsets = append(offsets, uintptr(size))
+			size += int(ft.Size())
+		}
+	}
+
+	br := &gobranch{v: val}
+	buf := &c_buffer{}
+	buf.reserve(size, nil)
+
+	c_name := C.CString(name)
+	defer C.free(unsafe.Pointer(c_name))
+	c_leaflist := C.CString(leaflist)
+	defer C.free(unsafe.Pointer(c_leaflist))
+
+	b := C.CRoot_Tree_Branch2(t.c, c_name, buf.ptr, c_leaflist, C.int32_t(bufsiz))
+	if b == nil {
+		buf.free()
+		return nil, fmt.Errorf("croot.Tree.Branch2: could not create branch [%s] (leaflist [%s])", name, leaflist)
+	}
+	br.br = &branch_impl{c: b}
+	br.free = buf.free
+	br.fill = func() error {
+		switch typ.Kind() {
+		case reflect.String:
+			str := val.String()
+			buf.reserve(len(str)+1, br.br)
+			dst := (*[1 << 30]byte)(buf.ptr)[: len(str)+1 : len(str)+1]
+			copy(dst, str)
+			dst[len(str)] = 0
+		case reflect.Slice:
+			n := val.Len() * int(typ.Elem().Size())
+			buf.reserve(n, br.br)
+			if n > 0 {
+				C.memcpy(buf.ptr, unsafe.Pointer(val.Pointer()), C.size_t(n))
+			}
+		case reflect.Struct:
+			for i, offset := range offsets {
+				f := val.Field(i)
+				C.memcpy(
+					unsafe.Pointer(uintptr(buf.ptr)+offset),
+					unsafe.Pointer(f.UnsafeAddr()),
+					C.size_t(f.Type().Size()),
+				)
+			}
+		default:
+			C.memcpy(buf.ptr, unsafe.Pointer(val.UnsafeAddr()), C.size_t(size))
+		}
+		return nil
+	}
+	t.branches[name] = br
+	return br.br, nil
+}
+
+// c_buffer is a C-allocated buffer holding the values of a branch
+type c_buffer struct {
+	ptr  unsafe.Pointer
+	size int
+}
+
+// reserve makes sure the buffer holds at least n bytes, re-addressing br
+// (if any) when the buffer moves.
+func (buf *c_buffer) reserve(n int, br *branch_impl) {
+	if buf.ptr != nil && n <= buf.size {
+		return
+	}
+	if n < 2*buf.size {
+		n = 2 * buf.size
+	}
+	if n < 1 {
+		n = 1
+	}
+	buf.ptr = C.realloc(buf.ptr, C.size_t(n))
+	buf.size = n
+	if br != nil {
+		C.CRoot_Branch_SetAddress(br.c, buf.ptr)
+	}
+}
+
+func (buf *c_buffer) free() {
+	C.free(buf.ptr)
+	buf.ptr = nil
+	buf.size = 0
+}
+
+// cxx_builtins maps the kinds of builtins to the name of their C++ type
+var cxx_builtins = map[reflect.Kind]string{
+	reflect.Bool:    "bool",
+	reflect.Int8:    "char",
+	reflect.Uint8:   "unsigned char",
+	reflect.Int16:   "short",
+	reflect.Uint16:  "unsigned short",
+	reflect.Int32:   "int",
+	reflect.Uint32:  "unsigned int",
+	reflect.Int64:   "Long64_t",
+	reflect.Uint64:  "ULong64_t",
+	reflect.Float32: "float",
+	reflect.Float64: "double",
+}
+
+// is_builtin returns whether values of kind k have a fixed-size C++
+// equivalent.
+func is_builtin(k reflect.Kind) bool {
+	_, ok := cxx_builtins[k]
+	return ok
+}
+
+// Draw evaluates varexp ("x", "x:y", ...) for all the entries passing the
+// selection and returns the number of selected rows.
+// Draw always runs in "goff" mode: no graphics is produced and the
+// computed columns are retrieved with GetV1...GetV4, GetVal and GetW.
+// Only GetEstimate() rows are kept: Draw raises the estimate to the number
+// of entries of the Tree, and draws again with a large enough estimate when
+// array expressions select more rows than that.
+func (t *tree_impl) Draw(varexp, selection string, option Option, nentries, firstentry int64) (int64, error) {
+	opt := string(option)
+	if !strings.Contains(strings.ToLower(opt), "goff") {
+		opt += " goff"
+	}
+
+	c_varexp := C.CString(varexp)
+	defer C.free(unsafe.Pointer(c_varexp))
+	c_selection := C.CString(selection)
+	defer C.free(unsafe.Pointer(c_selection))
+	c_option := C.CString(opt)
+	defer C.free(unsafe.Pointer(c_option))
+
+	if n := t.GetEntries(); t.GetEstimate() < n {
+		t.SetEstimate(n)
+	}
+
+	draw := func() int64 {
+		return int64(C.CRoot_Tree_Draw(
+			t.c,
+			c_varexp, c_selection,
+			(*C.CRoot_Option)(c_option),
+			C.int64_t(nentries), C.int64_t(firstentry),
+		))
+	}
+	n := draw()
+	if n > t.GetEstimate() {
+		// array expressions: the buffers only kept part of the rows.
+		t.SetEstimate(n + 1)
+		n = draw()
+	}
+	if n < 0 {
+		return n, fmt.Errorf("croot.Tree.Draw: error drawing [%s] with selection [%s]", varexp, selection)
+	}
+	return n, nil
+}
+
+// BuildIndex builds a ROOT index of the entries of the Tree from the
+// major and minor expressions (e.g. "run" and "event").
+func (t *tree_impl) BuildIndex(major, minor string) (int, error) {
+	if minor == "" {
+		minor = "0"
+	}
+	c_major := C.CString(major)
+	defer C.free(unsafe.Pointer(c_major))
+	c_minor := C.CString(minor)
+	defer C.free(unsafe.Pointer(c_minor))
+
+	n := int(C.CRoot_Tree_BuildIndex(t.c, c_major, c_minor))
+	if n <= 0 {
+		return n, fmt.Errorf("croot.Tree.BuildIndex: could not build index [%s, %s]", major, minor)
+	}
+	return n, nil
+}
+
+// BuildGoIndex builds a pure-Go index of the entries of the Tree from the
+// values of the major and minor branches, which must have been bound with
+// SetBranchAddress. It is used by GetEntryWithIndex when the Tree has no
+// ROOT index.
+func (t *tree_impl) BuildGoIndex(major, minor string) (int, error) {
+	idx, err := new_goindex(t, major, minor)
+	if err != nil {
+		return 0, err
+	}
+	t.goindex = idx
+	return len(idx.entries), nil
+}
+
+// CloneTree returns a copy of the first nentries entries of t (all of
+// them if nentries is negative, only the structure if it is 0), attached
+// to the current directory.
+// Only active branches are copied (see SetBranchStatus) and the addresses
+// of the copy's branches are shared with the ones of t.
+func (t *tree_impl) CloneTree(nentries int64) (Tree, error) {
+	c := C.CRoot_Tree_CloneTree(t.c, C.int64_t(nentries))
+	if c == nil {
+		return nil, fmt.Errorf("croot.Tree.CloneTree: could not clone tree [%s]", t.GetName())
+	}
+	return &tree_impl{c: c, branches: make(map[string]*gobranch)}, nil
+}
+
+// CopyTree returns a copy of the entries of t passing selection, attached
+// to the current directory.
+// Only active branches are copied (see SetBranchStatus).
+func (t *tree_impl) CopyTree(selection string) (Tree, error) {
+	c_sel := C.CString(selection)
+	defer C.free(unsafe.Pointer(c_sel))
+	c := C.CRoot_Tree_CopyTree(t.c, c_sel)
+	if c == nil {
+		return nil, fmt.Errorf("croot.Tree.CopyTree: could not copy tree [%s] with selection [%s]", t.GetName(), selection)
+	}
+	return &tree_impl{c: c, branches: make(map[string]*gobranch)}, nil
+}
+
 func (t *tree_impl) Fill() (int, error) {
 	// fmt.Printf("=== fill ===...\n")
 	for _, br := range t.branches {
-		br.c.SetValue(br.v)
+		switch {
+		case br.fill != nil:
+			err := br.fill()
+			if err != nil {
+				return -1, err
+			}
+		case br.col == nil && br.cols == nil:
+			br.c.SetValue(br.v)
+		}
 	}
 	nb := int(C.CRoot_Tree_Fill(t.c))
 	// fmt.Printf("=== fill ===... [done]\n")
@@ -307,8 +797,18 @@ func (t *tree_impl) GetEntries() int64 {
 
 func (t *tree_impl) GetEntry(entry int64, getall int) int {
 	//fmt.Fprintf(os.Stderr, ">> GetEntry(%v, %v)...\n", entry, getall)
+	if t.learning {
+		t.StopCacheLearningPhase()
+	}
 	nbytes := C.CRoot_Tree_GetEntry(t.c, C.int64_t(entry), C.int32_t(getall))
 	if nbytes > 0 {
+		// a Chain moved on to a new Tree: C-buffers have to be looked up again.
+		if n := t.GetTreeNumber(); n != t.treenum {
+			for _, br := range t.branches {
+				br.valid = false
+			}
+			t.treenum = n
+		}
 		for nn, br := range t.branches {
 			err := br.update_from_c(t, nn)
 			if err != nil {
@@ -320,6 +820,68 @@ func (t *tree_impl) GetEntry(entry int64, getall int) int {
 	return int(nbytes)
 }
 
+func (t *tree_impl) GetEntryList() EntryList {
+	c := C.CRoot_Tree_GetEntryList(t.c)
+	if c == nil {
+		return nil
+	}
+	return &entrylist_impl{c: c}
+}
+
+// GetEntryNumber returns the entry number of the i-th entry passing the
+// EntryList attached to the Tree (or i if there is none), -1 past the end.
+func (t *tree_impl) GetEntryNumber(entry int64) int64 {
+	return int64(C.CRoot_Tree_GetEntryNumber(t.c, C.int64_t(entry)))
+}
+
+// GetEntryFromList reads the i-th entry passing the EntryList attached to
+// the Tree (the i-th entry if there is none), like GetEntry does.
+// It returns -1 past the end of the list (and 0, like GetEntry, past the
+// end of the Tree):
+//
+//	for i := int64(0); tree.GetEntryFromList(i, 1) > 0; i++ {
+//	    // use the values bound with SetBranchAddress
+//	}
+func (t *tree_impl) GetEntryFromList(i int64, getall int) int {
+	entry := t.GetEntryNumber(i)
+	if entry < 0 {
+		return -1
+	}
+	return t.GetEntry(entry, getall)
+}
+
+// GetEntryNumberWithIndex returns the entry number matching the (major,
+// minor) index values, or -1 if there is none.
+// The ROOT index is used if any, the Go one (see BuildGoIndex) otherwise.
+func (t *tree_impl) GetEntryNumberWithIndex(major, minor int64) int64 {
+	if !c2bool(C.CRoot_Tree_HasTreeIndex(t.c)) && t.goindex != nil {
+		return t.goindex.entry(major, minor)
+	}
+	return int64(C.CRoot_Tree_GetEntryNumberWithIndex(t.c, C.int64_t(major), C.int64_t(minor)))
+}
+
+// GetEntryWithIndex reads the entry matching the (major, minor) index
+// values, like GetEntry does. It returns -1 if there is no such entry.
+func (t *tree_impl) GetEntryWithIndex(major, minor int64) int {
+	entry := t.GetEntryNumberWithIndex(major, minor)
+	if entry < 0 {
+		return -1
+	}
+	return t.GetEntry(entry, 1)
+}
+
+func (t *tree_impl) GetAutoFlush() int64 {
+	return int64(C.CRoot_Tree_GetAutoFlush(t.c))
+}
+
+func (t *tree_impl) GetAutoSave() int64 {
+	return int64(C.CRoot_Tree_GetAutoSave(t.c))
+}
+
+func (t *tree_impl) GetEstimate() int64 {
+	return int64(C.CRoot_Tree_GetEstimate(t.c))
+}
+
 func (t *tree_impl) GetLeaf(name string) Leaf {
 	c_name := C.CString(name)
 	defer C.free(unsafe.Pointer(c_name))
@@ -330,6 +892,16 @@ func (t *tree_impl) GetLeaf(name string) Leaf {
 	return &leaf_impl{c: c}
 }
 
+// GetTotBytes returns the uncompressed size (in bytes) of the entries of t.
+func (t *tree_impl) GetTotBytes() int64 {
+	return int64(C.CRoot_Tree_GetTotBytes(t.c))
+}
+
+// GetTreeNumber returns the index of the current Tree of a Chain (0 for a Tree)
+func (t *tree_impl) GetTreeNumber() int {
+	return int(C.CRoot_Tree_GetTreeNumber(t.c))
+}
+
 func (t *tree_impl) GetListOfBranches() []Branch {
 	c := C.CRoot_Tree_GetListOfBranches(t.c)
 	objs := objarray_impl{c: c}
@@ -358,64 +930,64 @@ func (t *tree_impl) GetSelectedRows() int64 {
 	return int64(C.CRoot_Tree_GetSelectedRows(t.c))
 }
 
-func (t *tree_impl) GetVal(i int) []float64 {
-	c_data := C.CRoot_Tree_GetVal(t.c, C.int32_t(i))
-	sz := t.GetSelectedRows()
+// get_column returns a copy of the sz first values of a Draw-buffer,
+// which holds at most estimate values.
+func get_column(c_data *C.double, sz, estimate int64) []float64 {
+	if c_data == nil {
+		return nil
+	}
+	if sz > estimate {
+		sz = estimate
+	}
+	if sz < 0 {
+		sz = 0
+	}
 	d := make([]float64, sz)
-	for j := int64(0); j != sz; sz++ {
+	for j := int64(0); j != sz; j++ {
 		d[j] = float64(C._go_croot_double_at(c_data, C.int(j)))
 	}
 	return d
 }
 
+// GetVal returns a copy of the i-th column computed by the last call to Draw.
+func (t *tree_impl) GetVal(i int) []float64 {
+	c_data := C.CRoot_Tree_GetVal(t.c, C.int32_t(i))
+	return get_column(c_data, t.GetSelectedRows(), t.GetEstimate())
+}
+
+// GetV1 returns a copy of the first column computed by the last call to Draw.
 func (t *tree_impl) GetV1() []float64 {
 	c_data := C.CRoot_Tree_GetV1(t.c)
-	sz := t.GetSelectedRows()
-	d := make([]float64, sz)
-	for j := int64(0); j != sz; sz++ {
-		d[j] = float64(C._go_croot_double_at(c_data, C.int(j)))
-	}
-	return d
+	return get_column(c_data, t.GetSelectedRows(), t.GetEstimate())
 }
 
+// GetV2 returns a copy of the second column computed by the last call to Draw.
 func (t *tree_impl) GetV2() []float64 {
 	c_data := C.CRoot_Tree_GetV2(t.c)
-	sz := t.GetSelectedRows()
-	d := make([]float64, sz)
-	for j := int64(0); j != sz; sz++ {
-		d[j] = float64(C._go_croot_double_at(c_data, C.int(j)))
-	}
-	return d
+	return get_column(c_data, t.GetSelectedRows(), t.GetEstimate())
 }
 
+// GetV3 returns a copy of the third column computed by the last call to Draw.
 func (t *tree_impl) GetV3() []float64 {
 	c_data := C.CRoot_Tree_GetV3(t.c)
-	sz := t.GetSelectedRows()
-	d := make([]float64, sz)
-	for j := int64(0); j != sz; sz++ {
-		d[j] = float64(C._go_croot_double_at(c_data, C.int(j)))
-	}
-	return d
+	return get_column(c_data, t.GetSelectedRows(), t.GetEstimate())
 }
 
+// GetV4 returns a copy of the fourth column computed by the last call to Draw.
 func (t *tree_impl) GetV4() []float64 {
 	c_data := C.CRoot_Tree_GetV4(t.c)
-	sz := t.GetSelectedRows()
-	d := make([]float64, sz)
-	for j := int64(0); j != sz; sz++ {
-		d[j] = float64(C._go_croot_double_at(c_data, C.int(j)))
-	}
-	return d
+	return get_column(c_data, t.GetSelectedRows(), t.GetEstimate())
 }
 
+// GetW returns a copy of the weights computed by the last call to Draw.
 func (t *tree_impl) GetW() []float64 {
 	c_data := C.CRoot_Tree_GetW(t.c)
-	sz := t.GetSelectedRows()
-	d := make([]float64, sz)
-	for j := int64(0); j != sz; sz++ {
-		d[j] = float64(C._go_croot_double_at(c_data, C.int(j)))
-	}
-	return d
+	return get_column(c_data, t.GetSelectedRows(), t.GetEstimate())
+}
+
+// GetZipBytes returns the compressed size (in bytes) of the entries of t.
+func (t *tree_impl) GetZipBytes() int64 {
+	return int64(C.CRoot_Tree_GetZipBytes(t.c))
 }
 
 func (t *tree_impl) LoadTree(entry int64) int64 {
@@ -432,6 +1004,26 @@ func (t *tree_impl) Print(option Option) {
 	C.CRoot_Tree_Print(t.c, (*C.CRoot_Option)(c_option))
 }
 
+// SetAutoFlush sets when the baskets of t are flushed to disk: every
+// autof entries if autof > 0, or every -autof bytes if autof < 0.
+// Flushing bounds the memory used while filling t.
+func (t *tree_impl) SetAutoFlush(autof int64) {
+	C.CRoot_Tree_SetAutoFlush(t.c, C.int64_t(autof))
+}
+
+// SetAutoSave sets when the header of t is saved to disk: every autos
+// entries if autos > 0, or every -autos bytes if autos < 0.
+// The entries saved so far can then be recovered if the writing process
+// dies before the file is closed.
+func (t *tree_impl) SetAutoSave(autos int64) {
+	C.CRoot_Tree_SetAutoSave(t.c, C.int64_t(autos))
+}
+
+// SetBranchAddress connects obj, a pointer to a Go value, to the branch
+// name: obj is updated at each GetEntry.
+// Structs bound to a leaflist branch (e.g. "a/I:b/D") must have one field
+// per leaf, of the same type and in the same order.
+// It returns the TTree::SetBranchAddress code (< 0 on failure).
 func (t *tree_impl) SetBranchAddress(name string, obj interface{}) int32 {
 	c_name := C.CString(name)
 	defer C.free(unsafe.Pointer(c_name))
@@ -447,6 +1039,15 @@ func (t *tree_impl) SetBranchAddress(name string, obj interface{}) int32 {
 		val = reflect.Indirect(ptr)
 	}
 
+	switch val.Kind() {
+	case reflect.Array, reflect.Slice, reflect.String:
+		return t.set_column_address(name, val)
+	case reflect.Struct:
+		if t.is_leaflist(name) {
+			return t.set_leaflist_address(name, val)
+		}
+	}
+
 	br := &gobranch{v: val}
 	typ := br.v.Type()
 	// register the type with Reflex
@@ -457,12 +1058,141 @@ func (t *tree_impl) SetBranchAddress(name string, obj interface{}) int32 {
 	br.addr = unsafe.Pointer(&br.cptr)
 	//
 
-	rc := C.CRoot_Tree_SetBranchAddress(t.c, c_name, br.addr, nil)
+	// "alias.branch" names of friend branches are set on the friend tree.
+	ft, bname := t.resolve_friend(name)
+	if ft != t {
+		c_bname := C.CString(bname)
+		defer C.free(unsafe.Pointer(c_bname))
+		c_name = c_bname
+	}
+
+	rc := C.CRoot_Tree_SetBranchAddress(ft.c, c_name, br.addr, nil)
 
 	t.branches[name] = br
+	if t.cache && ft == t {
+		t.AddBranchToCache(name, true)
+		t.learning = true
+	}
 	return int32(rc)
 }
 
+// set_column_address connects val to the leaf of the branch name. The
+// values of arrays, std::vectors and C-strings are copied from the buffers
+// ROOT allocated for the leaf, after each GetEntry.
+func (t *tree_impl) set_column_address(name string, val reflect.Value) int32 {
+	col, code, err := t.leaf_column(name)
+	if err != nil {
+		return code
+	}
+	if col.Type != val.Type() {
+		return -2 // TTree::kMismatch
+	}
+	t.branches[name] = &gobranch{v: val, valid: true, col: &col}
+	if t.cache {
+		if ft, _ := t.resolve_friend(name); ft == t {
+			t.AddBranchToCache(name, true)
+			t.learning = true
+		}
+	}
+	return 0
+}
+
+// is_leaflist returns whether name is a plain TBranch (created from a
+// leaflist), as opposed to a branched object.
+func (t *tree_impl) is_leaflist(name string) bool {
+	ft, bname := t.resolve_friend(name)
+	b := ft.GetBranch(bname)
+	return b != nil && b.ClassName() == "TBranch"
+}
+
+// set_leaflist_address connects the fields of the struct val to the leaves
+// of the branch name. ROOT packs the leaves of a leaflist without padding:
+// the values are copied from the buffers of the leaves, after each GetEntry.
+func (t *tree_impl) set_leaflist_address(name string, val reflect.Value) int32 {
+	cols, code, err := t.leaflist_columns(name, val.Type())
+	if err != nil {
+		return code
+	}
+	t.branches[name] = &gobranch{v: val, valid: true, cols: cols}
+	if t.cache {
+		if ft, _ := t.resolve_friend(name); ft == t {
+			t.AddBranchToCache(name, true)
+			t.learning = true
+		}
+	}
+	return 0
+}
+
+// leaflist_columns describes the leaves of the branch name, making sure
+// they match the fields of the struct typ, in order.
+// Failures come with the TTree::SetBranchAddress code of the problem (see
+// leaf_column).
+func (t *tree_impl) leaflist_columns(name string, typ reflect.Type) ([]Column, int32, error) {
+	ft, bname := t.resolve_friend(name)
+	c_name := C.CString(bname)
+	defer C.free(unsafe.Pointer(c_name))
+
+	c_br := C.CRoot_Tree_GetBranch(ft.c, c_name)
+	if c_br == nil {
+		return nil, -5, fmt.Errorf("croot.Tree.SetBranchAddress: no branch [%s]", name)
+	}
+	br := &branch_impl{c: c_br}
+	c_leaves := C.CRoot_Branch_GetListOfLeaves(c_br)
+	n := int(C.CRoot_ObjArray_GetSize(c_leaves))
+	if n != typ.NumField() {
+		return nil, -2, fmt.Errorf(
+			"croot.Tree.SetBranchAddress: branch [%s] has %d leaves, [%v] has %d fields",
+			name, n, typ, typ.NumField(),
+		)
+	}
+	cols := make([]Column, n)
+	for i := range cols {
+		leaf := &leaf_impl{c: (C.CRoot_Leaf)(unsafe.Pointer(C.CRoot_ObjArray_At(c_leaves, C.int64_t(i))))}
+		col, err := new_column(name+"."+leaf.GetName(), leaf, br)
+		if err != nil {
+			return nil, -2, err
+		}
+		if f := typ.Field(i); col.Type != f.Type || f.PkgPath != "" {
+			return nil, -2, fmt.Errorf(
+				"croot.Tree.SetBranchAddress: leaf [%s] of type [%v] can not be bound to field [%s] of type [%v]",
+				col.Name, col.Type, f.Name, f.Type,
+			)
+		}
+		cols[i] = col
+	}
+	return cols, 0, nil
+}
+
+// leaf_column describes the (single) leaf of the branch name.
+// Failures come with the TTree::SetBranchAddress code of the problem:
+// -5 (kMissingBranch) if there is no such branch, -2 (kMismatch) if its
+// leaves can not be bound to a Go value.
+func (t *tree_impl) leaf_column(name string) (Column, int32, error) {
+	ft, bname := t.resolve_friend(name)
+	c_name := C.CString(bname)
+	defer C.free(unsafe.Pointer(c_name))
+
+	var c_leaf C.CRoot_Leaf
+	if c_br := C.CRoot_Tree_GetBranch(ft.c, c_name); c_br != nil {
+		c_leaves := C.CRoot_Branch_GetListOfLeaves(c_br)
+		if n := int(C.CRoot_ObjArray_GetSize(c_leaves)); n != 1 {
+			return Column{}, -2, fmt.Errorf("croot.Tree.SetBranchAddress: branch [%s] has %d leaves", name, n)
+		}
+		c_leaf = (C.CRoot_Leaf)(unsafe.Pointer(C.CRoot_ObjArray_At(c_leaves, 0)))
+	} else {
+		c_leaf = C.CRoot_Tree_GetLeaf(ft.c, c_name)
+	}
+	if c_leaf == nil {
+		return Column{}, -5, fmt.Errorf("croot.Tree.SetBranchAddress: no branch [%s]", name)
+	}
+	leaf := &leaf_impl{c: c_leaf}
+	col, err := new_column(name, leaf, leaf.GetBranch().(*branch_impl))
+	if err != nil {
+		return col, -2, err
+	}
+	return col, 0, nil
+}
+
 func (t *tree_impl) SetBranchStatus(name string, status bool) uint32 {
 	c_found := C.uint32_t(0)
 	c_name := C.CString(name)
@@ -471,6 +1201,56 @@ func (t *tree_impl) SetBranchStatus(name string, status bool) uint32 {
 	return uint32(c_found)
 }
 
+// SetCacheLearnEntries sets the number of entries the TTreeCache reads
+// to learn which branches are used.
+func (t *tree_impl) SetCacheLearnEntries(n int) {
+	C.CRoot_Tree_SetCacheLearnEntries(t.c, C.int32_t(n))
+}
+
+// SetCacheSize sets the size (in bytes) of the TTreeCache of t. A size of
+// 0 disables the cache.
+// When the cache is enabled, the branches bound through SetBranchAddress
+// (before or after the call to SetCacheSize) are added to the cache and
+// its learning phase is stopped at the next GetEntry: only these branches
+// are then read through the cache.
+func (t *tree_impl) SetCacheSize(cachesize int64) {
+	C.CRoot_Tree_SetCacheSize(t.c, C.int64_t(cachesize))
+	t.cache = cachesize > 0
+	t.learning = false
+	if !t.cache {
+		return
+	}
+	for name := range t.branches {
+		if ft, _ := t.resolve_friend(name); ft != t {
+			continue
+		}
+		t.AddBranchToCache(name, true)
+		t.learning = true
+	}
+}
+
+// SetEntryList restricts the entries visited through GetEntryNumber and
+// GetEntryFromList to the ones of elist. A nil elist removes the current list.
+func (t *tree_impl) SetEntryList(elist EntryList) {
+	if elist == nil {
+		C.CRoot_Tree_SetEntryList(t.c, nil)
+		return
+	}
+	C.CRoot_Tree_SetEntryList(t.c, elist.(*entrylist_impl).c)
+}
+
+func (t *tree_impl) SetEstimate(nentries int64) {
+	C.CRoot_Tree_SetEstimate(t.c, C.int64_t(nentries))
+}
+
+// StopCacheLearningPhase stops the learning phase of the TTreeCache: only
+// the branches used so far, or explicitly added, are then read through the
+// cache.
+func (t *tree_impl) StopCacheLearningPhase() {
+	C.CRoot_Tree_StopCacheLearningPhase(t.c)
+	t.learning = false
+}
+
 func (t *tree_impl) Write(name string, option, bufsize int) int {
 	if len(name) != 0 {
 		c_name := C.CString(name)
@@ -483,7 +1263,7 @@ func (t *tree_impl) Write(name string, option, bufsize int) int {
 
 func init() {
 	cnvmap["TTree"] = func(o c_object) Object {
-		return &tree_impl{c: (C.CRoot_Tree)(o.cptr())}
+		return &tree_impl{c: (C.CRoot_Tree)(o.cptr()), branches: make(map[string]*gobranch)}
 	}
 }
 
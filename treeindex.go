@@ -0,0 +1,82 @@
+package croot
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// goindex is a pure-Go index of the entries of a Tree, keyed by the
+// (major, minor) values of two branches bound with SetBranchAddress.
+type goindex struct {
+	major   string
+	minor   string
+	entries map[[2]int64]int64
+}
+
+// index_value converts the value of a bound branch into an index key
+func index_value(v reflect.Value) (int64, error) {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return int64(v.Float()), nil
+	}
+	return 0, fmt.Errorf("croot.goindex: invalid index value kind [%v]", v.Kind())
+}
+
+// new_goindex reads all the entries of t and records the entry number of
+// each (major, minor) pair. An empty minor name means a minor value of 0.
+func new_goindex(t *tree_impl, major, minor string) (*goindex, error) {
+	br_major, ok := t.branches[major]
+	if !ok {
+		return nil, fmt.Errorf("croot.Tree.BuildGoIndex: branch [%s] is not bound (see SetBranchAddress)", major)
+	}
+	var br_minor *gobranch
+	if minor != "" {
+		br_minor, ok = t.branches[minor]
+		if !ok {
+			return nil, fmt.Errorf("croot.Tree.BuildGoIndex: branch [%s] is not bound (see SetBranchAddress)", minor)
+		}
+	}
+
+	nentries := t.GetEntries()
+	idx := &goindex{
+		major:   major,
+		minor:   minor,
+		entries: make(map[[2]int64]int64, nentries),
+	}
+	for i := int64(0); i < nentries; i++ {
+		if t.GetEntry(i, 1) <= 0 {
+			return nil, fmt.Errorf("croot.Tree.BuildGoIndex: could not read entry [%d]", i)
+		}
+		var key [2]int64
+		var err error
+		key[0], err = index_value(br_major.v)
+		if err != nil {
+			return nil, err
+		}
+		if br_minor != nil {
+			key[1], err = index_value(br_minor.v)
+			if err != nil {
+				return nil, err
+			}
+		}
+		// like TTreeIndex, keep the first entry with a given key.
+		if _, dup := idx.entries[key]; !dup {
+			idx.entries[key] = i
+		}
+	}
+	return idx, nil
+}
+
+func (idx *goindex) entry(major, minor int64) int64 {
+	entry, ok := idx.entries[[2]int64{major, minor}]
+	if !ok {
+		return -1
+	}
+	return entry
+}
+
+// EOF
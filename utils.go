@@ -20,7 +20,7 @@ func c2bool(b C.CRoot_Bool) bool {
 }
 
 func bool2c(b bool) C.CRoot_Bool {
-	if true {
+	if b {
 		return C.CRoot_Bool(1)
 	}
 	return C.CRoot_Bool(0)
@@ -0,0 +1,290 @@
+package croot
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+// ColumnSpec describes a column of a Tree written by a Writer
+type ColumnSpec struct {
+	Name string       // name of the branch
+	Kind reflect.Kind // Go kind of the values (bool, sized integers, floats or string)
+	Len  int          // number of elements of fixed-size arrays (0 for scalars), maximum length of strings
+}
+
+// default_string_len is the maximum length of strings if not specified
+const default_string_len = 255
+
+// leaf_codes maps the Go kinds to the type codes of ROOT leaflists
+var leaf_codes = map[reflect.Kind]string{
+	reflect.Bool:    "O",
+	reflect.Int8:    "B",
+	reflect.Uint8:   "b",
+	reflect.Int16:   "S",
+	reflect.Uint16:  "s",
+	reflect.Int32:   "I",
+	reflect.Uint32:  "i",
+	reflect.Int64:   "L",
+	reflect.Uint64:  "l",
+	reflect.Float32: "F",
+	reflect.Float64: "D",
+	reflect.String:  "C",
+}
+
+// Leaflist returns the ROOT leaflist describing the column ("x/D",
+// "v[3]/F", ...).
+func (spec ColumnSpec) Leaflist() string {
+	code := leaf_codes[spec.Kind]
+	if spec.Len > 0 && spec.Kind != reflect.String {
+		return fmt.Sprintf("%s[%d]/%s", spec.Name, spec.Len, code)
+	}
+	return spec.Name + "/" + code
+}
+
+// elem_type returns the Go type of the elements of the column, as stored
+// in the Tree buffer.
+func (spec ColumnSpec) elem_type() reflect.Type {
+	switch spec.Kind {
+	case reflect.String:
+		// C-strings are stored in a NUL-terminated buffer.
+		n := spec.Len
+		if n <= 0 {
+			n = default_string_len
+		}
+		return reflect.ArrayOf(n+1, reflect.TypeOf(uint8(0)))
+	case reflect.Bool:
+		// Bool_t is stored as a single byte.
+		return reflect.TypeOf(uint8(0))
+	case reflect.Int8:
+		return reflect.TypeOf(int8(0))
+	case reflect.Uint8:
+		return reflect.TypeOf(uint8(0))
+	case reflect.Int16:
+		return reflect.TypeOf(int16(0))
+	case reflect.Uint16:
+		return reflect.TypeOf(uint16(0))
+	case reflect.Int32:
+		return reflect.TypeOf(int32(0))
+	case reflect.Uint32:
+		return reflect.TypeOf(uint32(0))
+	case reflect.Int64:
+		return reflect.TypeOf(int64(0))
+	case reflect.Uint64:
+		return reflect.TypeOf(uint64(0))
+	case reflect.Float32:
+		return reflect.TypeOf(float32(0))
+	case reflect.Float64:
+		return reflect.TypeOf(float64(0))
+	}
+	return nil
+}
+
+// Writer fills a Tree from rows of values whose layout is only known at
+// runtime, described by a list of ColumnSpecs: a branch is created for
+// each column, without any Go type declared ahead of time.
+type Writer struct {
+	tree  Tree
+	specs []ColumnSpec
+	vals  []reflect.Value // buffers connected to the branches
+	index map[string]int
+}
+
+// NewWriter creates the branches described by specs in t and returns a
+// Writer filling them.
+func NewWriter(t Tree, specs []ColumnSpec, bufsiz int) (*Writer, error) {
+	w := &Writer{
+		tree:  t,
+		specs: make([]ColumnSpec, len(specs)),
+		vals:  make([]reflect.Value, len(specs)),
+		index: make(map[string]int, len(specs)),
+	}
+	copy(w.specs, specs)
+
+	for i, spec := range w.specs {
+		if spec.Name == "" || strings.ContainsAny(spec.Name, "/[]:") {
+			return nil, fmt.Errorf("croot.NewWriter: invalid column name [%s]", spec.Name)
+		}
+		if _, dup := w.index[spec.Name]; dup {
+			return nil, fmt.Errorf("croot.NewWriter: duplicate column [%s]", spec.Name)
+		}
+		if spec.Len < 0 {
+			return nil, fmt.Errorf("croot.NewWriter: invalid length [%d] for column [%s]", spec.Len, spec.Name)
+		}
+		typ := spec.elem_type()
+		if typ == nil {
+			return nil, fmt.Errorf("croot.NewWriter: unsupported kind [%v] for column [%s]", spec.Kind, spec.Name)
+		}
+		if spec.Len > 0 && spec.Kind != reflect.String {
+			typ = reflect.ArrayOf(spec.Len, typ)
+		}
+
+		ptr := reflect.New(typ)
+		_, err := t.Branch2(spec.Name, ptr.Interface(), spec.Leaflist(), bufsiz)
+		if err != nil {
+			return nil, err
+		}
+		w.vals[i] = ptr.Elem()
+		w.index[spec.Name] = i
+	}
+	return w, nil
+}
+
+// Columns returns the description of the columns written by w.
+func (w *Writer) Columns() []ColumnSpec {
+	return w.specs
+}
+
+// Write fills the Tree with a row holding a value per column, in the order
+// of the columns. Numbers are converted to the kind of their column, as
+// long as they are represented without truncation nor overflow (integers
+// may be rounded into floats), and arrays are given as Go arrays or slices
+// of the column length.
+func (w *Writer) Write(row []interface{}) (int, error) {
+	if len(row) != len(w.specs) {
+		return 0, fmt.Errorf("croot.Writer.Write: expected %d values, got %d", len(w.specs), len(row))
+	}
+	for i, v := range row {
+		err := w.set(i, v)
+		if err != nil {
+			return 0, fmt.Errorf("croot.Writer.Write: %v", err)
+		}
+	}
+	return w.tree.Fill()
+}
+
+// WriteMap fills the Tree with a row holding values indexed by column
+// name. Missing columns are filled with zeros.
+func (w *Writer) WriteMap(row map[string]interface{}) (int, error) {
+	for name := range row {
+		if _, ok := w.index[name]; !ok {
+			return 0, fmt.Errorf("croot.Writer.WriteMap: no column [%s]", name)
+		}
+	}
+	for i, spec := range w.specs {
+		v, ok := row[spec.Name]
+		if !ok {
+			w.vals[i].Set(reflect.Zero(w.vals[i].Type()))
+			continue
+		}
+		err := w.set(i, v)
+		if err != nil {
+			return 0, fmt.Errorf("croot.Writer.WriteMap: %v", err)
+		}
+	}
+	return w.tree.Fill()
+}
+
+// set stores v into the buffer of the i-th column.
+func (w *Writer) set(i int, v interface{}) error {
+	spec := w.specs[i]
+	dst := w.vals[i]
+	src := reflect.ValueOf(v)
+	if spec.Kind == reflect.String {
+		return set_string(spec, dst, v)
+	}
+	if spec.Len == 0 {
+		return set_value(spec, dst, src)
+	}
+	if !src.IsValid() || (src.Kind() != reflect.Array && src.Kind() != reflect.Slice) {
+		return fmt.Errorf("column [%s]: expected an array of %d values, got %T", spec.Name, spec.Len, v)
+	}
+	if src.Len() != spec.Len {
+		return fmt.Errorf("column [%s]: expected %d values, got %d", spec.Name, spec.Len, src.Len())
+	}
+	for j := 0; j < spec.Len; j++ {
+		err := set_value(spec, dst.Index(j), src.Index(j))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// set_string stores the string v into the C-string buffer dst.
+func set_string(spec ColumnSpec, dst reflect.Value, v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("column [%s]: expected a string, got %T", spec.Name, v)
+	}
+	if len(str) >= dst.Len() {
+		return fmt.Errorf("column [%s]: string of length %d exceeds the maximum length (%d)", spec.Name, len(str), dst.Len()-1)
+	}
+	if strings.IndexByte(str, 0) >= 0 {
+		return fmt.Errorf("column [%s]: string holds a NUL byte", spec.Name)
+	}
+	buf := dst.Slice(0, dst.Len()).Bytes()
+	n := copy(buf, str)
+	buf[n] = 0
+	return nil
+}
+
+// set_value converts src to the type of dst and stores it.
+func set_value(spec ColumnSpec, dst, src reflect.Value) error {
+	if src.Kind() == reflect.Interface {
+		src = src.Elem()
+	}
+	if !src.IsValid() {
+		return fmt.Errorf("column [%s]: nil value", spec.Name)
+	}
+	if spec.Kind == reflect.Bool {
+		if src.Kind() != reflect.Bool {
+			return fmt.Errorf("column [%s]: expected a bool, got %v", spec.Name, src.Type())
+		}
+		if src.Bool() {
+			dst.SetUint(1)
+		} else {
+			dst.SetUint(0)
+		}
+		return nil
+	}
+	switch src.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if !fits_in(dst, src) {
+			return fmt.Errorf("column [%s]: value %v can not be represented as %v", spec.Name, src, dst.Type())
+		}
+		dst.Set(src.Convert(dst.Type()))
+		return nil
+	}
+	return fmt.Errorf("column [%s]: expected a number, got %v", spec.Name, src.Type())
+}
+
+// fits_in returns whether the number src converts to the type of dst
+// without being truncated or wrapped around.
+// Integers converted to floats may be rounded.
+func fits_in(dst, src reflect.Value) bool {
+	switch dst.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch src.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return !dst.OverflowInt(src.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return src.Uint() <= math.MaxInt64 && !dst.OverflowInt(int64(src.Uint()))
+		}
+		f := src.Float()
+		return f == math.Trunc(f) && f >= -(1<<63) && f < 1<<63 && !dst.OverflowInt(int64(f))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		switch src.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return src.Int() >= 0 && !dst.OverflowUint(uint64(src.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return !dst.OverflowUint(src.Uint())
+		}
+		f := src.Float()
+		return f == math.Trunc(f) && f >= 0 && f < 1<<64 && !dst.OverflowUint(uint64(f))
+
+	case reflect.Float32:
+		switch src.Kind() {
+		case reflect.Float32, reflect.Float64:
+			f := src.Float()
+			return math.IsNaN(f) || math.IsInf(f, 0) || !dst.OverflowFloat(f)
+		}
+	}
+	return true
+}
+
+// EOF